@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+)
+
+// Backend controls single backends of services at runtime
+func Backend(cmd *cli.Cmd) {
+	cmd.Command("drain", "takes a backend out of rotation by setting its weight to 0", BackendDrain)
+	cmd.Command("enable", "puts a drained backend back into rotation", BackendEnable)
+}
+
+// BackendDrain drains a backend of a service
+func BackendDrain(cmd *cli.Cmd) {
+	cmd.Spec = "[--remove-after=<duration>] SERVICE ADDRESS"
+	var (
+		removeAfter = cmd.StringOpt("remove-after", "", "remove backend from service after this grace period, e.g. 30s")
+		service     = cmd.StringArg("SERVICE", "", "name of service")
+		address     = cmd.StringArg("ADDRESS", "", "address of backend, as shown by service show")
+	)
+
+	cmd.Action = func() {
+		var d time.Duration
+		if *removeAfter != "" {
+			var err error
+			d, err = time.ParseDuration(*removeAfter)
+			if err != nil {
+				log.WithField("err", err).Fatal("Invalid --remove-after duration.")
+			}
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		_, err := client.DrainBackend(ctx, &localinterface.BackendRequest{
+			Service:         *service,
+			Address:         *address,
+			RemoveAfterSecs: int64(d / time.Second),
+		})
+		if err != nil {
+			log.WithField("err", err).Fatal("error draining backend.")
+		}
+	}
+}
+
+// BackendEnable clears a drain of a backend
+func BackendEnable(cmd *cli.Cmd) {
+	cmd.Spec = "SERVICE ADDRESS"
+	var (
+		service = cmd.StringArg("SERVICE", "", "name of service")
+		address = cmd.StringArg("ADDRESS", "", "address of backend, as shown by service show")
+	)
+
+	cmd.Action = func() {
+		client, ctx, done := daemonClient()
+		defer done()
+
+		_, err := client.EnableBackend(ctx, &localinterface.BackendRequest{
+			Service: *service,
+			Address: *address,
+		})
+		if err != nil {
+			log.WithField("err", err).Fatal("error enabling backend.")
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/aschmidt75/ipvsmesh/config"
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"github.com/aschmidt75/ipvsmesh/model"
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Config contains commands for working with configuration files
+func Config(cmd *cli.Cmd) {
+	cmd.Command("diff", "shows how a candidate configuration would change the applied ipvs model", ConfigDiff)
+	cmd.Command("validate", "checks a configuration file or directory without a running daemon", ConfigValidate)
+	cmd.Command("migrate", "converts configuration files to the current apiVersion", ConfigMigrate)
+	cmd.Command("render", "prints a configuration file or directory as it is read by the daemon", ConfigRender)
+}
+
+type changeView struct {
+	Kind        string `json:"kind" yaml:"kind"`
+	Service     string `json:"service" yaml:"service"`
+	ServiceName string `json:"serviceName,omitempty" yaml:"serviceName,omitempty"`
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
+	Field       string `json:"field,omitempty" yaml:"field,omitempty"`
+	Old         string `json:"old,omitempty" yaml:"old,omitempty"`
+	New         string `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// ConfigDiff asks the running daemon to build the ipvs model for a candidate
+// configuration and prints the differences to the applied model. References
+// to environment variables and secret files as well as parameters are resolved
+// here, as the daemon does not resolve them for its clients.
+func ConfigDiff(cmd *cli.Cmd) {
+	cmd.Spec = "--config=<configfile> [-o|--output=<format>]"
+	var (
+		configfile = cmd.StringOpt("config", "", "candidate configuration file or directory")
+		output     = cmd.StringOpt("o output", outputTable, "output format: table, json or yaml")
+	)
+
+	cmd.Action = func() {
+		if !isValidOutputFormat(*output) {
+			log.WithField("output", *output).Fatal("Invalid output format.")
+		}
+
+		cfg, err := config.ReadModel(*configfile)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to read configuration.")
+		}
+		if err := config.ExpandParameters(cfg); err != nil {
+			log.WithField("err", err).Fatal("unable to expand parameters.")
+		}
+		cfg.Parameters = nil
+		b, err := yaml.Marshal(cfg)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to format configuration.")
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		r, err := client.DiffConfig(ctx, &localinterface.DiffRequest{Config: b})
+		if err != nil {
+			log.WithField("err", err).Fatal("error computing diff.")
+		}
+		if len(r.Errors) > 0 {
+			for _, e := range r.Errors {
+				fmt.Fprintln(os.Stderr, e)
+			}
+			log.Fatal("Configuration has errors.")
+		}
+		for _, w := range r.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+
+		v := make([]changeView, len(r.Changes))
+		for idx, c := range r.Changes {
+			v[idx] = changeView{
+				Kind:        c.Kind,
+				Service:     c.Service,
+				ServiceName: c.ServiceName,
+				Destination: c.Destination,
+				Field:       c.Field,
+				Old:         c.Old,
+				New:         c.New,
+			}
+		}
+
+		if *output == outputTable {
+			if len(v) == 0 {
+				fmt.Println("No changes.")
+				return
+			}
+			w := newTable()
+			row(w, "CHANGE", "SERVICE", "NAME", "DESTINATION", "FIELD", "OLD", "NEW")
+			for _, c := range v {
+				row(w, c.Kind, c.Service, c.ServiceName, c.Destination, c.Field, c.Old, c.New)
+			}
+			w.Flush()
+			return
+		}
+		if err := printStructured(*output, v); err != nil {
+			log.WithField("err", err).Fatal("unable to format diff.")
+		}
+	}
+}
+
+// ConfigValidate checks a configuration file or directory offline, without
+// a running daemon. Problems are printed with file and line, and cause a
+// non-zero exit code.
+func ConfigValidate(cmd *cli.Cmd) {
+	cmd.Spec = "[--config=<configfile>]"
+	var (
+		configfile = cmd.StringOpt("config", config.Config().DefaultConfigFile, "configuration file or directory to validate")
+	)
+
+	cmd.Action = func() {
+		err := config.Validate(*configfile)
+		if err == nil {
+			fmt.Printf("%s: configuration is valid.\n", *configfile)
+			return
+		}
+
+		if errs, ok := err.(config.Errors); ok {
+			for _, e := range errs {
+				fmt.Fprintln(os.Stderr, e)
+			}
+			fmt.Fprintf(os.Stderr, "%s: %d problem(s) found.\n", *configfile, len(errs))
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		cli.Exit(1)
+	}
+}
+
+// ConfigMigrate converts a configuration file, or all files of a configuration
+// directory, to the current apiVersion. Without --write, converted files are
+// printed to stdout.
+func ConfigMigrate(cmd *cli.Cmd) {
+	cmd.Spec = "[-w|--write] [--config=<configfile>]"
+	var (
+		write      = cmd.BoolOpt("w write", false, "rewrite files in place instead of printing them")
+		configfile = cmd.StringOpt("config", config.Config().DefaultConfigFile, "configuration file or directory to migrate")
+	)
+
+	cmd.Action = func() {
+		files, err := config.ConfigFiles(*configfile)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to read configuration.")
+		}
+
+		for idx, file := range files {
+			if *write {
+				from, err := config.MigrateFile(file)
+				if err != nil {
+					log.WithFields(log.Fields{"file": file, "err": err}).Fatal("unable to migrate configuration file.")
+				}
+				if from == model.CurrentAPIVersion {
+					fmt.Printf("%s: already at %s\n", file, model.CurrentAPIVersion)
+					continue
+				}
+				fmt.Printf("%s: migrated from %s to %s\n", file, from, model.CurrentAPIVersion)
+				continue
+			}
+
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				log.WithField("err", err).Fatal("unable to read configuration file.")
+			}
+			res, _, err := config.MigrateBytes(b)
+			if err != nil {
+				log.WithFields(log.Fields{"file": file, "err": err}).Fatal("unable to migrate configuration file.")
+			}
+			if len(files) > 1 {
+				if idx > 0 {
+					fmt.Println("---")
+				}
+				fmt.Printf("# %s\n", file)
+			}
+			os.Stdout.Write(res)
+		}
+	}
+}
+
+// ConfigRender prints a configuration file or directory as it is read by the
+// daemon, i.e. merged, migrated and with parameters expanded. With --effective,
+// unset options of services are resolved from globals.defaults. Secrets are
+// replaced by a placeholder.
+func ConfigRender(cmd *cli.Cmd) {
+	cmd.Spec = "[--effective] [--config=<configfile>]"
+	var (
+		effective  = cmd.BoolOpt("effective", false, "resolve defaults of all services")
+		configfile = cmd.StringOpt("config", config.Config().DefaultConfigFile, "configuration file or directory to render")
+	)
+
+	cmd.Action = func() {
+		cfg, err := config.ReadMaskedModel(*configfile)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to read configuration.")
+		}
+		if err := config.ExpandMaskedParameters(cfg); err != nil {
+			log.WithField("err", err).Fatal("unable to expand parameters.")
+		}
+
+		if *effective {
+			for idx, service := range cfg.Services {
+				service.Globals = &cfg.Globals
+				cfg.Services[idx] = service.Effective()
+			}
+		}
+
+		b, err := yaml.Marshal(cfg)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to format configuration.")
+		}
+		os.Stdout.Write(b)
+	}
+}
@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"log/syslog"
 	"net"
 	"os"
@@ -11,6 +14,7 @@ import (
 	"github.com/aschmidt75/ipvsmesh/config"
 	"github.com/aschmidt75/ipvsmesh/daemon"
 	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"github.com/aschmidt75/ipvsmesh/systemd"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -24,24 +28,56 @@ import (
 func Daemon(cmd *cli.Cmd) {
 	cmd.Command("start", "starts the daemon", DaemonStart)
 	cmd.Command("stop", "stops the daemon", DaemonStop)
+	cmd.Command("status", "shows status of the running daemon", DaemonStatus)
+	cmd.Command("reload", "forces the daemon to re-read its configuration", DaemonReload)
+	cmd.Command("loglevel", "shows or changes log levels of the running daemon", DaemonLogLevel)
+	cmd.Command("pause", "stops applying updates to ipvs (maintenance mode)", DaemonPause)
+	cmd.Command("resume", "resumes applying updates to ipvs", DaemonResume)
 }
 
 // DaemonStart starts the daemon either on foreground or background mode
 func DaemonStart(cmd *cli.Cmd) {
-	cmd.Spec = "[-f|--foreground] [--log-file=<logfile>] [--sudo] [--gid=<groupid>] [--config=<configfile>] [--once]"
+	cmd.Spec = "[-f|--foreground] [--log-file=<logfile>] [--sudo] [--gid=<groupid>] [--config=<configfile>] [--config-ca=<cafile>] [--config-cache=<cachefile>] [--config-poll=<seconds>] [--state-file=<statefile>] [--listen=<address>] [--http=<address>] [--pid-file=<pidfile>] [--once]"
 	var (
-		foreground = cmd.BoolOpt("f foreground", false, "Run in foreground, do not daemonize")
-		once       = cmd.BoolOpt("o once", false, "Run loop only once, exit after first cycle")
-		logfile    = cmd.StringOpt("log-file", "", "optional log file destination. Default destination is syslog")
-		sudo       = cmd.BoolOpt("sudo", false, "use sudo when daemonizing")
-		groupID    = cmd.IntOpt("gid", -1, "optional group ID for socket and log file creation")
-		configfile = cmd.StringOpt("config", config.Config().DefaultConfigFile, "optional filename of config file.")
+		foreground  = cmd.BoolOpt("f foreground", false, "Run in foreground, do not daemonize")
+		once        = cmd.BoolOpt("o once", false, "Run loop only once, exit after first cycle")
+		logfile     = cmd.StringOpt("log-file", "", "optional log file destination. Default destination is syslog")
+		sudo        = cmd.BoolOpt("sudo", false, "use sudo when daemonizing")
+		groupID     = cmd.IntOpt("gid", -1, "optional group ID for socket and log file creation")
+		configfile  = cmd.StringOpt("config", config.Config().DefaultConfigFile, "optional config file, directory of *.yaml config files, or http(s) URL to poll.")
+		configCA    = cmd.StringOpt("config-ca", config.Config().ConfigCAFile, "optional CA file to verify the server of a https config URL with")
+		configCache = cmd.StringOpt("config-cache", config.Config().ConfigCacheFile, "optional file to cache a remote config in, to fall back to if the server cannot be reached. Must be owned by root and not writable by others")
+		configPoll  = cmd.IntOpt("config-poll", config.Config().ConfigPollSecs, "interval in seconds of polling a remote config")
+		statefile   = cmd.StringOpt("state-file", config.Config().StateFile, "optional file to persist services registered at runtime.")
+		listen      = cmd.StringOpt("listen", config.Config().DaemonListenAddress, "optional TCP address to listen on for remote management, e.g. :7443. Requires --tls and --tlsca")
+		httpAddr    = cmd.StringOpt("http", config.Config().HTTPListenAddress, "optional address to serve /healthz, /readyz and /metrics on, e.g. :8080")
+		pidfile     = cmd.StringOpt("pid-file", config.Config().DaemonPidFile, "optional pidfile, locked while running. Default: socket path with .pid suffix, in /run when running as root")
 	)
 
 	cmd.Action = func() {
-		_, err := os.Stat(config.Config().DaemonSocketPath)
-		if err == nil {
-			log.WithField("socket", config.Config().DaemonSocketPath).Fatal("Socket file already exists. Maybe another instance is already running? Remove socket file otherwise.")
+		if *listen != "" && !config.Config().TLS {
+			log.WithField("listen", *listen).Fatal("Listening on TCP requires --tls.")
+		}
+		if *listen != "" && config.Config().TLSCAFile == "" {
+			log.WithField("listen", *listen).Fatal("Listening on TCP requires --tlsca to verify client certificates.")
+		}
+		config.Config().DaemonListenAddress = *listen
+		config.Config().HTTPListenAddress = *httpAddr
+
+		socketPath := config.Config().DaemonSocketPath
+		if *pidfile == "" {
+			*pidfile = daemon.DefaultPidFile(socketPath)
+		}
+
+		// refuse to start a second instance
+		if err := daemon.CheckPidFile(*pidfile, socketPath); err != nil {
+			log.Fatal(err)
+		}
+
+		// systemd supervises the process it started, do not daemonize
+		if os.Getenv("NOTIFY_SOCKET") != "" && !*foreground {
+			log.Debug("Started by systemd, running in foreground")
+			*foreground = true
 		}
 
 		if config.Config().DaemonizeFlag {
@@ -117,6 +153,23 @@ func DaemonStart(cmd *cli.Cmd) {
 		} else {
 			log.Debug("Running in foreground")
 
+			pf, err := daemon.AcquirePidFile(*pidfile, socketPath)
+			if err != nil {
+				log.WithField("pidfile", *pidfile).Fatal(err)
+			}
+			defer pf.Release()
+
+			// with socket activation, systemd has created the socket file
+			if systemd.ListenFDs() == 0 {
+				removed, err := daemon.RemoveStaleSocket(socketPath)
+				if err != nil {
+					log.WithField("socket", socketPath).Fatal(err)
+				}
+				if removed {
+					log.WithField("socket", socketPath).Warn("Removed stale socket file of a previous daemon.")
+				}
+			}
+
 			ds := daemon.NewService(*groupID)
 
 			configUpdateCh := make(daemon.ConfigUpdateChanType)
@@ -126,13 +179,25 @@ func DaemonStart(cmd *cli.Cmd) {
 			onceCh := make(chan struct{})
 
 			// Create an applier which reads from the channel and applies updates. controls service workers
-			configApplier := daemon.NewConfigApplierWorker(configUpdateCh, ipvsUpdateCh, publisherConfigUpdateCh)
+			configApplier := daemon.NewConfigApplierWorker(configUpdateCh, ipvsUpdateCh, publisherConfigUpdateCh, *statefile)
 			ds.Register(&configApplier.StoppableByChan)
 			log.WithField("s", configApplier).Trace("registered")
 			go configApplier.Worker()
 
-			// create a watcher on the config file. It will send updates to configUpdateCh
-			configWatcher := daemon.NewConfigWatcherWorker(*configfile, configUpdateCh, *once)
+			// create a watcher on the config file, or poll a remote config. It will send updates to configUpdateCh
+			var configWatcher *daemon.ConfigWatcherWorker
+			if config.IsRemote(*configfile) {
+				if *configPoll <= 0 {
+					log.WithField("config-poll", *configPoll).Fatal("Polling interval must be positive.")
+				}
+				source, err := config.NewRemoteSource(*configfile, *configCA, *configCache)
+				if err != nil {
+					log.WithField("err", err).Fatal("Unable to set up remote config.")
+				}
+				configWatcher = daemon.NewRemoteConfigWatcherWorker(source, time.Duration(*configPoll)*time.Second, configUpdateCh, *once)
+			} else {
+				configWatcher = daemon.NewConfigWatcherWorker(*configfile, configUpdateCh, *once)
+			}
 			ds.Register(&configWatcher.StoppableByChan)
 			log.WithField("s", configWatcher).Trace("registered")
 			go configWatcher.Worker()
@@ -151,6 +216,11 @@ func DaemonStart(cmd *cli.Cmd) {
 			log.WithField("s", ipvsApplier).Trace("registered")
 			go ipvsApplier.Worker()
 
+			ds.ConfigWatcher = configWatcher
+			ds.ConfigApplier = configApplier
+			ds.IPVSApplier = ipvsApplier
+			ds.Publisher = publisherWorker
+
 			go func() {
 				for {
 					select {
@@ -169,32 +239,42 @@ func DaemonStart(cmd *cli.Cmd) {
 }
 
 func connect() *grpc.ClientConn {
-	_, err := os.Stat(config.Config().DaemonSocketPath)
-	if err != nil {
-		log.WithFields(log.Fields{"socket": config.Config().DaemonSocketPath, "err": err}).Fatal("Socket file not present. Maybe daemon is not running?")
+	// local unix socket, or a remote daemon via --host
+	network, target := "unix", config.Config().DaemonSocketPath
+	if config.Config().DaemonHost != "" {
+		if !config.Config().TLS {
+			log.WithField("host", config.Config().DaemonHost).Fatal("Connecting to a remote daemon requires --tls.")
+		}
+		network, target = "tcp", config.Config().DaemonHost
+	} else {
+		_, err := os.Stat(target)
+		if err != nil {
+			log.WithFields(log.Fields{"socket": target, "err": err}).Fatal("Socket file not present. Maybe daemon is not running?")
+		}
 	}
 
 	var conn *grpc.ClientConn
+	var err error
 
 	//	dialOptions := grpc.WithContextDialer(func(ctx context.Context, addr string, timeout time.Duration) (net.Conn, error) {
 	dialOptions := grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
-		return net.DialTimeout("unix", addr, timeout)
+		return net.DialTimeout(network, addr, timeout)
 	})
 	if config.Config().TLS {
-		creds, err := credentials.NewClientTLSFromFile(config.Config().TLSCertFile, "ipvsmesh-grpc-tls-comm")
+		creds, err := clientCredentials()
 		if err != nil {
-			log.Fatal("unable to load TLS key or certificate from file. Check --tls* parameters.")
+			log.WithField("err", err).Fatal("unable to load TLS key, certificate or CA from file. Check --tls* parameters.")
 		}
 
 		conn, err = grpc.Dial(
-			config.Config().DaemonSocketPath, grpc.WithTransportCredentials(creds), dialOptions)
+			target, grpc.WithTransportCredentials(creds), dialOptions)
 		if err != nil {
 			log.WithField("err", err).Fatal("Unable to create TLS dialing socket.")
 		}
 
 	} else {
 		conn, err = grpc.Dial(
-			config.Config().DaemonSocketPath, grpc.WithInsecure(), dialOptions)
+			target, grpc.WithInsecure(), dialOptions)
 		if err != nil {
 			log.WithField("err", err).Fatal("Unable to create dialing socket.")
 		}
@@ -203,6 +283,49 @@ func connect() *grpc.ClientConn {
 	return conn
 }
 
+// clientCredentials verifies the daemon against --tlsca and presents --tlscert/--tlskey
+// as client certificate. Without --tlsca, --tlscert is the daemon's (self-signed) certificate.
+func clientCredentials() (credentials.TransportCredentials, error) {
+	c := config.Config()
+	if c.TLSCAFile == "" {
+		return credentials.NewClientTLSFromFile(c.TLSCertFile, c.TLSServerName)
+	}
+
+	b, err := ioutil.ReadFile(c.TLSCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no CA certificates found in %s", c.TLSCAFile)
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		ServerName: c.TLSServerName,
+	}
+	if c.TLSCertFile != "" && c.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// daemonClient connects to the daemon and returns a client together with
+// a context bounded by the configured timeout. done must be called when finished.
+func daemonClient() (client localinterface.DaemonServiceClient, ctx context.Context, done func()) {
+	conn := connect()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Config().DaemonConnTimeoutSecs)*time.Second)
+
+	return localinterface.NewDaemonServiceClient(conn), ctx, func() {
+		cancel()
+		conn.Close()
+	}
+}
+
 // DaemonStop stops the daemon by sending the stop command to background process
 func DaemonStop(cmd *cli.Cmd) {
 	cmd.Action = func() {
@@ -210,11 +333,10 @@ func DaemonStop(cmd *cli.Cmd) {
 		conn := connect()
 		defer conn.Close()
 
+		// no deadline, stopping includes applying the shutdown policy,
+		// which may drain services for a while.
 		client := localinterface.NewDaemonServiceClient(conn)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Config().DaemonConnTimeoutSecs)*time.Second)
-		defer cancel()
-
-		_, err := client.Stop(ctx, &localinterface.Empty{})
+		_, err := client.Stop(context.Background(), &localinterface.Empty{})
 		if err != nil {
 			log.WithField("err", err).Error("error stopping daemon.")
 			return
@@ -222,3 +344,116 @@ func DaemonStop(cmd *cli.Cmd) {
 
 	}
 }
+
+// DaemonReload forces the daemon to re-read its configuration
+func DaemonReload(cmd *cli.Cmd) {
+	cmd.Action = func() {
+		client, ctx, done := daemonClient()
+		defer done()
+
+		r, err := client.Reload(ctx, &localinterface.Empty{})
+		if err != nil {
+			log.WithField("err", err).Fatal("error reloading daemon configuration.")
+		}
+
+		if !r.Applied {
+			for _, e := range r.Errors {
+				fmt.Fprintln(os.Stderr, e)
+			}
+			log.Fatal("Configuration has errors, not applied.")
+		}
+	}
+}
+
+// DaemonLogLevel changes log levels of the running daemon, or shows them
+func DaemonLogLevel(cmd *cli.Cmd) {
+	cmd.Spec = "[--component=<component>] [--revert-after=<duration>] [-o|--output=<format>] [LEVEL]"
+	var (
+		component   = cmd.StringOpt("component", "", "component to change, e.g. ipvsapplier, configwatcher, serviceworker, publisher or plugin:<name>. Default: all")
+		revertAfter = cmd.StringOpt("revert-after", "", "restore previous level after this duration, at least 1s, e.g. 10m")
+		output      = cmd.StringOpt("o output", outputTable, "output format: table, json or yaml")
+		level       = cmd.StringArg("LEVEL", "", "new log level: trace, debug, info, warn, error. Shows levels if omitted.")
+	)
+
+	cmd.Action = func() {
+		if !isValidOutputFormat(*output) {
+			log.WithField("output", *output).Fatal("Invalid output format.")
+		}
+
+		var d time.Duration
+		if *revertAfter != "" {
+			var err error
+			d, err = time.ParseDuration(*revertAfter)
+			if err != nil {
+				log.WithField("err", err).Fatal("Invalid --revert-after duration.")
+			}
+			// the daemon reverts after full seconds
+			if d < time.Second {
+				log.WithField("revert-after", *revertAfter).Fatal("Invalid --revert-after duration, must be at least 1s.")
+			}
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		r, err := client.SetLogLevel(ctx, &localinterface.LogLevelRequest{
+			Level:           *level,
+			Component:       *component,
+			RevertAfterSecs: int64(d / time.Second),
+		})
+		if err != nil {
+			log.WithField("err", err).Fatal("error setting log level.")
+		}
+
+		type levelView struct {
+			Component string `json:"component" yaml:"component"`
+			Level     string `json:"level" yaml:"level"`
+			Override  bool   `json:"override" yaml:"override"`
+		}
+		v := make([]levelView, len(r.Levels))
+		for idx, l := range r.Levels {
+			v[idx] = levelView{Component: l.Component, Level: l.Level, Override: l.Override}
+		}
+
+		if *output == outputTable {
+			w := newTable()
+			row(w, "COMPONENT", "LEVEL", "OVERRIDE")
+			for _, l := range v {
+				c := l.Component
+				if c == "" {
+					c = "(global)"
+				}
+				row(w, c, l.Level, l.Override)
+			}
+			w.Flush()
+			return
+		}
+		if err := printStructured(*output, v); err != nil {
+			log.WithField("err", err).Fatal("unable to format log levels.")
+		}
+	}
+}
+
+// DaemonPause puts the daemon into maintenance mode
+func DaemonPause(cmd *cli.Cmd) {
+	cmd.Action = func() {
+		client, ctx, done := daemonClient()
+		defer done()
+
+		if _, err := client.Pause(ctx, &localinterface.Empty{}); err != nil {
+			log.WithField("err", err).Fatal("error pausing daemon.")
+		}
+	}
+}
+
+// DaemonResume ends maintenance mode of the daemon
+func DaemonResume(cmd *cli.Cmd) {
+	cmd.Action = func() {
+		client, ctx, done := daemonClient()
+		defer done()
+
+		if _, err := client.Resume(ctx, &localinterface.Empty{}); err != nil {
+			log.WithField("err", err).Fatal("error resuming daemon.")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+type eventView struct {
+	Time    string            `json:"time" yaml:"time"`
+	Type    string            `json:"type" yaml:"type"`
+	Service string            `json:"service,omitempty" yaml:"service,omitempty"`
+	Message string            `json:"message" yaml:"message"`
+	Fields  map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+func newEventView(e *localinterface.Event) eventView {
+	return eventView{
+		Time:    time.Unix(0, e.Time).Format(time.RFC3339Nano),
+		Type:    e.Type,
+		Service: e.Service,
+		Message: e.Message,
+		Fields:  e.Fields,
+	}
+}
+
+// printEvent writes a single event. json output is one object
+// per line, yaml output is one document per event.
+func printEvent(format string, v eventView) error {
+	switch format {
+	case outputJSON:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case outputYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", string(b))
+	default:
+		fmt.Printf("%s %-22s %-20s %s %s\n", v.Time, v.Type, v.Service, v.Message, formatLabels(v.Fields))
+	}
+	return nil
+}
+
+// Events shows recent events of the daemon and optionally follows the event stream
+func Events(cmd *cli.Cmd) {
+	cmd.Spec = "[-f|--follow] [--service=<name>] [-o|--output=<format>]"
+	var (
+		follow  = cmd.BoolOpt("f follow", false, "keep streaming new events")
+		service = cmd.StringOpt("service", "", "show only events of given service")
+		output  = cmd.StringOpt("o output", outputTable, "output format: table, json or yaml")
+	)
+
+	cmd.Action = func() {
+		if !isValidOutputFormat(*output) {
+			log.WithField("output", *output).Fatal("Invalid output format.")
+		}
+
+		conn := connect()
+		defer conn.Close()
+
+		// streams may last forever, do not use the default timeout here
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		client := localinterface.NewDaemonServiceClient(conn)
+		stream, err := client.Events(ctx, &localinterface.EventsRequest{
+			Follow:  *follow,
+			Service: *service,
+		})
+		if err != nil {
+			log.WithField("err", err).Fatal("error querying events.")
+		}
+
+		for {
+			e, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				log.WithField("err", err).Fatal("error receiving events.")
+			}
+			if err := printEvent(*output, newEventView(e)); err != nil {
+				log.WithField("err", err).Fatal("unable to format event.")
+			}
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"time"
@@ -16,29 +18,90 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// Kinds of certificates issued by GenSignedKeyFiles
+const (
+	CertKindServer = "server"
+	CertKindClient = "client"
+)
+
 // GenKeyFiles generates a EC P256 private key and x509 certificate, writes to files
 // with given prefix. CN is set, lifetime is 1yr
 func GenKeyFiles(prefix, cn string) error {
-	var priv *ecdsa.PrivateKey
-	var err error
+	priv, template, err := newKeyAndTemplate(cn, 365*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	template.DNSNames = []string{cn}
 
-	priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	pubk := &priv.PublicKey
+	return writeKeyFiles(prefix, template, template, priv, priv)
+}
 
+// GenCAFiles generates a EC P256 private key and a self-signed CA certificate
+// with given CN and lifetime, writes to files with given prefix.
+func GenCAFiles(prefix, cn string, lifetime time.Duration) error {
+	priv, template, err := newKeyAndTemplate(cn, lifetime)
 	if err != nil {
 		return err
 	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	return writeKeyFiles(prefix, template, template, priv, priv)
+}
+
+// GenSignedKeyFiles generates a EC P256 private key and x509 certificate signed by
+// the CA with files at caPrefix, writes to files with given prefix. kind is either
+// CertKindServer or CertKindClient. Server certificates carry cn and dnsNames as SANs.
+func GenSignedKeyFiles(prefix, cn, kind string, dnsNames []string, caPrefix string, lifetime time.Duration) error {
+	caCert, caKey, err := readKeyFiles(caPrefix)
+	if err != nil {
+		return err
+	}
+	if !caCert.IsCA {
+		return fmt.Errorf("%s.cert is not a CA certificate", caPrefix)
+	}
+
+	priv, template, err := newKeyAndTemplate(cn, lifetime)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case CertKindServer:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.DNSNames = append([]string{cn}, dnsNames...)
+	case CertKindClient:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	default:
+		return fmt.Errorf("unknown certificate kind: %s", kind)
+	}
+
+	// do not outlive the CA
+	if template.NotAfter.After(caCert.NotAfter) {
+		template.NotAfter = caCert.NotAfter
+	}
+
+	return writeKeyFiles(prefix, template, caCert, priv, caKey)
+}
+
+// newKeyAndTemplate generates a EC P256 private key and a certificate
+// template with a random serial number, given CN and lifetime.
+func newKeyAndTemplate(cn string, lifetime time.Duration) (*ecdsa.PrivateKey, *x509.Certificate, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	var notBefore = time.Now()
-	var notAfter = notBefore.Add(365 * 24 * time.Hour)
+	var notAfter = notBefore.Add(lifetime)
 
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	template := x509.Certificate{
+	template := &x509.Certificate{
 		SerialNumber: serialNumber,
 		Subject: pkix.Name{
 			CommonName: cn,
@@ -47,11 +110,15 @@ func GenKeyFiles(prefix, cn string) error {
 		NotAfter:  notAfter,
 
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 	}
+	return priv, template, nil
+}
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, pubk, priv)
+// writeKeyFiles creates the certificate from template, signed by parent, and
+// writes it together with priv to <prefix>.cert and <prefix>.key
+func writeKeyFiles(prefix string, template, parent *x509.Certificate, priv, parentPriv *ecdsa.PrivateKey) error {
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &priv.PublicKey, parentPriv)
 	if err != nil {
 		return err
 	}
@@ -85,6 +152,37 @@ func GenKeyFiles(prefix, cn string) error {
 	return nil
 }
 
+// readKeyFiles reads a certificate and EC private key from <prefix>.cert and <prefix>.key
+func readKeyFiles(prefix string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("%s.cert", prefix))
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, errors.New("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err = ioutil.ReadFile(fmt.Sprintf("%s.key", prefix))
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ = pem.Decode(b)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, nil, errors.New("no PEM EC private key found")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
 // CmdGenerateKey implements the generation for a tls key
 func CmdGenerateKey(cmd *cli.Cmd) {
 	cmd.Action = func() {
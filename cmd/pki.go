@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/config"
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultCAPrefix = "ipvsmesh-ca"
+
+// PKI manages a CA and certificates for TLS communication with the daemon
+func PKI(cmd *cli.Cmd) {
+	cmd.Command("init-ca", "creates a CA key and certificate", PKIInitCA)
+	cmd.Command("issue", "issues certificates signed by the CA", func(cmd *cli.Cmd) {
+		cmd.Command("server", "issues a server certificate for the daemon", func(cmd *cli.Cmd) { PKIIssue(cmd, CertKindServer) })
+		cmd.Command("client", "issues a client certificate for an operator", func(cmd *cli.Cmd) { PKIIssue(cmd, CertKindClient) })
+	})
+}
+
+// PKIInitCA creates a new CA
+func PKIInitCA(cmd *cli.Cmd) {
+	cmd.Spec = "[--ca=<prefix>] [--cn=<cn>] [--days=<days>]"
+	var (
+		caPrefix = cmd.StringOpt("ca", defaultCAPrefix, "file prefix of CA, writes <prefix>.cert and <prefix>.key")
+		cn       = cmd.StringOpt("cn", "ipvsmesh-ca", "common name of CA")
+		days     = cmd.IntOpt("days", 3650, "lifetime of CA certificate in days")
+	)
+
+	cmd.Action = func() {
+		if _, err := os.Stat(fmt.Sprintf("%s.key", *caPrefix)); err == nil {
+			log.WithField("ca", *caPrefix).Fatal("CA key already exists, will not overwrite it.")
+		}
+
+		if err := GenCAFiles(*caPrefix, *cn, time.Duration(*days)*24*time.Hour); err != nil {
+			log.WithField("err", err).Fatal("unable to generate/write CA key/certificate file")
+		}
+		fmt.Printf("%s.cert\n%s.key\n", *caPrefix, *caPrefix)
+	}
+}
+
+// PKIIssue issues a server or client certificate signed by the CA
+func PKIIssue(cmd *cli.Cmd, kind string) {
+	cmd.Spec = "--cn=<cn> [--dns=<name>]... [--ca=<prefix>] [--out=<prefix>] [--days=<days>]"
+	var (
+		cn       = cmd.StringOpt("cn", "", "common name. For client certificates, this identifies the operator")
+		dnsNames = cmd.StringsOpt("dns", nil, fmt.Sprintf("additional DNS names of server certificates. %s is always included", config.Config().TLSServerName))
+		caPrefix = cmd.StringOpt("ca", defaultCAPrefix, "file prefix of CA")
+		out      = cmd.StringOpt("out", "", "file prefix of certificate, writes <prefix>.cert and <prefix>.key. Default: cn")
+		days     = cmd.IntOpt("days", 365, "lifetime of certificate in days")
+	)
+
+	cmd.Action = func() {
+		prefix := *out
+		if prefix == "" {
+			var err error
+			if prefix, err = certPrefix(*cn); err != nil {
+				log.WithField("err", err).Fatal("Invalid --cn, use --out to give a file prefix.")
+			}
+		}
+		for _, ext := range []string{"cert", "key"} {
+			if _, err := os.Stat(fmt.Sprintf("%s.%s", prefix, ext)); err == nil {
+				log.WithField("out", prefix).Fatalf("%s.%s already exists, will not overwrite it.", prefix, ext)
+			}
+		}
+
+		names := *dnsNames
+		if kind == CertKindServer && *cn != config.Config().TLSServerName {
+			names = append(names, config.Config().TLSServerName)
+		}
+
+		if err := GenSignedKeyFiles(prefix, *cn, kind, names, *caPrefix, time.Duration(*days)*24*time.Hour); err != nil {
+			log.WithField("err", err).Fatal("unable to generate/write key/certificate file")
+		}
+		fmt.Printf("%s.cert\n%s.key\n", prefix, prefix)
+	}
+}
+
+// certPrefix returns the default file prefix of a certificate issued
+// for cn. It must not contain path separators, so that files are
+// written to the current directory.
+func certPrefix(cn string) (string, error) {
+	if cn == "" || cn == "." || cn == ".." || strings.ContainsAny(cn, "/\\") {
+		return "", fmt.Errorf("common name %q is not a valid file name", cn)
+	}
+	return cn, nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import "testing"
+
+func TestCertPrefix(t *testing.T) {
+	tests := []struct {
+		cn  string
+		err bool
+	}{
+		{"ipvsmesh-daemon", false},
+		{"alice@example.com", false},
+		{"..alice", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{"../alice", true},
+		{"/etc/ipvsmesh/alice", true},
+		{"ops/alice", true},
+		{"ops\\alice", true},
+	}
+	for _, test := range tests {
+		res, err := certPrefix(test.cn)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.cn)
+			}
+			continue
+		}
+		if err != nil || res != test.cn {
+			t.Errorf("%q: got %q, %v", test.cn, res, err)
+		}
+	}
+}
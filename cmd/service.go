@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+)
+
+type serviceView struct {
+	Name               string        `json:"name" yaml:"name"`
+	Address            string        `json:"address" yaml:"address"`
+	Type               string        `json:"type" yaml:"type"`
+	Scheduler          string        `json:"scheduler" yaml:"scheduler"`
+	SchedFlags         []string      `json:"schedFlags,omitempty" yaml:"schedFlags,omitempty"`
+	Forward            string        `json:"forward" yaml:"forward"`
+	PersistenceTimeout int32         `json:"persistenceTimeout,omitempty" yaml:"persistenceTimeout,omitempty"`
+	Netmask            string        `json:"netmask,omitempty" yaml:"netmask,omitempty"`
+	OnePacket          bool          `json:"onePacket,omitempty" yaml:"onePacket,omitempty"`
+	Runtime            bool          `json:"runtime" yaml:"runtime"`
+	Source             string        `json:"source,omitempty" yaml:"source,omitempty"`
+	Backends           []backendView `json:"backends" yaml:"backends"`
+}
+
+type backendView struct {
+	Address        string            `json:"address" yaml:"address"`
+	Weight         int32             `json:"weight" yaml:"weight"`
+	UpperThreshold int32             `json:"upperThreshold,omitempty" yaml:"upperThreshold,omitempty"`
+	LowerThreshold int32             `json:"lowerThreshold,omitempty" yaml:"lowerThreshold,omitempty"`
+	AdditionalInfo map[string]string `json:"additionalInfo,omitempty" yaml:"additionalInfo,omitempty"`
+	State          string            `json:"state" yaml:"state"`
+}
+
+func newServiceView(s *localinterface.ServiceInfo) serviceView {
+	res := serviceView{
+		Name:               s.Name,
+		Address:            s.Address,
+		Type:               s.Type,
+		Scheduler:          s.Scheduler,
+		SchedFlags:         s.SchedFlags,
+		Forward:            s.Forward,
+		PersistenceTimeout: s.PersistenceTimeout,
+		Netmask:            s.Netmask,
+		OnePacket:          s.OnePacket,
+		Runtime:            s.Runtime,
+		Source:             s.Source,
+		Backends:           make([]backendView, len(s.Backends)),
+	}
+	for idx, b := range s.Backends {
+		res.Backends[idx] = backendView{
+			Address:        b.Address,
+			Weight:         b.Weight,
+			UpperThreshold: b.UpperThreshold,
+			LowerThreshold: b.LowerThreshold,
+			AdditionalInfo: b.AdditionalInfo,
+			State:          b.State,
+		}
+	}
+	return res
+}
+
+// persistence describes the persistence options of a service
+func persistence(v serviceView) string {
+	if v.PersistenceTimeout == 0 {
+		return "-"
+	}
+	if v.Netmask != "" {
+		return fmt.Sprintf("%ds, netmask %s", v.PersistenceTimeout, v.Netmask)
+	}
+	return fmt.Sprintf("%ds", v.PersistenceTimeout)
+}
+
+// threshold formats a connection threshold, 0 is no limit
+func threshold(n int32) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.Itoa(int(n))
+}
+
+// Service queries services and their backends from the daemon
+func Service(cmd *cli.Cmd) {
+	cmd.Command("list ls", "lists all services with their number of backends", ServiceList)
+	cmd.Command("show", "shows a single service with its backends", ServiceShow)
+	cmd.Command("add", "registers a service at runtime", ServiceAdd)
+	cmd.Command("remove rm", "removes a service registered at runtime", ServiceRemove)
+}
+
+// owner describes where a service has been defined
+func owner(runtime bool) string {
+	if runtime {
+		return "runtime"
+	}
+	return "config"
+}
+
+// source describes the config file a service has been read from
+func source(file string) string {
+	if file == "" {
+		return "-"
+	}
+	return file
+}
+
+// ServiceList lists all services known to the daemon
+func ServiceList(cmd *cli.Cmd) {
+	cmd.Spec = "[-o|--output=<format>]"
+	var (
+		output = cmd.StringOpt("o output", outputTable, "output format: table, json or yaml")
+	)
+
+	cmd.Action = func() {
+		if !isValidOutputFormat(*output) {
+			log.WithField("output", *output).Fatal("Invalid output format.")
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		r, err := client.ListServices(ctx, &localinterface.Empty{})
+		if err != nil {
+			log.WithField("err", err).Fatal("error querying services.")
+		}
+
+		v := make([]serviceView, len(r.Services))
+		for idx, s := range r.Services {
+			v[idx] = newServiceView(s)
+		}
+
+		if *output == outputTable {
+			w := newTable()
+			row(w, "NAME", "ADDRESS", "TYPE", "SCHED", "FORWARD", "BACKENDS", "OWNER", "SOURCE")
+			for _, s := range v {
+				row(w, s.Name, s.Address, s.Type, s.Scheduler, s.Forward, len(s.Backends), owner(s.Runtime), source(s.Source))
+			}
+			w.Flush()
+			return
+		}
+		if err := printStructured(*output, v); err != nil {
+			log.WithField("err", err).Fatal("unable to format services.")
+		}
+	}
+}
+
+// ServiceShow shows a single service with all of its backends
+func ServiceShow(cmd *cli.Cmd) {
+	cmd.Spec = "[-o|--output=<format>] NAME"
+	var (
+		output = cmd.StringOpt("o output", outputTable, "output format: table, json or yaml")
+		name   = cmd.StringArg("NAME", "", "name of service")
+	)
+
+	cmd.Action = func() {
+		if !isValidOutputFormat(*output) {
+			log.WithField("output", *output).Fatal("Invalid output format.")
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		r, err := client.GetService(ctx, &localinterface.ServiceRequest{Name: *name})
+		if err != nil {
+			log.WithField("err", err).Fatal("error querying service.")
+		}
+
+		v := newServiceView(r)
+		if *output == outputTable {
+			w := newTable()
+			row(w, "Name:", v.Name)
+			row(w, "Address:", v.Address)
+			row(w, "Type:", v.Type)
+			row(w, "Scheduler:", v.Scheduler)
+			if len(v.SchedFlags) > 0 {
+				row(w, "Scheduler flags:", strings.Join(v.SchedFlags, ", "))
+			}
+			row(w, "Forward:", v.Forward)
+			row(w, "Persistence:", persistence(v))
+			if v.OnePacket {
+				row(w, "One-packet:", "yes")
+			}
+			row(w, "Owner:", owner(v.Runtime))
+			row(w, "Source:", source(v.Source))
+			w.Flush()
+
+			fmt.Println()
+			w = newTable()
+			row(w, "BACKEND", "WEIGHT", "UPPER", "LOWER", "STATE", "INFO")
+			for _, b := range v.Backends {
+				row(w, b.Address, b.Weight, threshold(b.UpperThreshold), threshold(b.LowerThreshold), b.State, formatLabels(b.AdditionalInfo))
+			}
+			w.Flush()
+			return
+		}
+		if err := printStructured(*output, v); err != nil {
+			log.WithField("err", err).Fatal("unable to format service.")
+		}
+	}
+}
+
+// ServiceAdd registers a service from a yaml file at runtime
+func ServiceAdd(cmd *cli.Cmd) {
+	cmd.Spec = "-f|--file=<servicefile>"
+	var (
+		file = cmd.StringOpt("f file", "", "yaml file with a single service definition")
+	)
+
+	cmd.Action = func() {
+		b, err := ioutil.ReadFile(*file)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to read service file.")
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		if _, err := client.AddService(ctx, &localinterface.AddServiceRequest{Service: b}); err != nil {
+			log.WithField("err", err).Fatal("error adding service.")
+		}
+	}
+}
+
+// ServiceRemove removes a service registered at runtime
+func ServiceRemove(cmd *cli.Cmd) {
+	cmd.Spec = "NAME"
+	var (
+		name = cmd.StringArg("NAME", "", "name of service")
+	)
+
+	cmd.Action = func() {
+		client, ctx, done := daemonClient()
+		defer done()
+
+		if _, err := client.RemoveService(ctx, &localinterface.ServiceRequest{Name: *name}); err != nil {
+			log.WithField("err", err).Fatal("error removing service.")
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	cli "github.com/jawher/mow.cli"
+	log "github.com/sirupsen/logrus"
+)
+
+type statusView struct {
+	StartTime     string              `json:"startTime" yaml:"startTime"`
+	Uptime        string              `json:"uptime" yaml:"uptime"`
+	ConfigFile    string              `json:"configFile" yaml:"configFile"`
+	ConfigModTime string              `json:"configModTime" yaml:"configModTime"`
+	Services      []serviceWorkerView `json:"services" yaml:"services"`
+	Publishers    []publisherView     `json:"publishers" yaml:"publishers"`
+	LastApply     applyView           `json:"lastApply" yaml:"lastApply"`
+	Overrides     []overrideView      `json:"overrides" yaml:"overrides"`
+	Paused        bool                `json:"paused" yaml:"paused"`
+	PausedSince   string              `json:"pausedSince,omitempty" yaml:"pausedSince,omitempty"`
+	Pending       bool                `json:"pendingChanges" yaml:"pendingChanges"`
+}
+
+type overrideView struct {
+	Service  string `json:"service" yaml:"service"`
+	Address  string `json:"address" yaml:"address"`
+	Weight   int32  `json:"weight" yaml:"weight"`
+	Since    string `json:"since" yaml:"since"`
+	RemoveAt string `json:"removeAt,omitempty" yaml:"removeAt,omitempty"`
+	Removed  bool   `json:"removed" yaml:"removed"`
+}
+
+type serviceWorkerView struct {
+	Name        string `json:"name" yaml:"name"`
+	Type        string `json:"type" yaml:"type"`
+	Address     string `json:"address" yaml:"address"`
+	Runtime     bool   `json:"runtime" yaml:"runtime"`
+	Source      string `json:"source,omitempty" yaml:"source,omitempty"`
+	NumBackends int32  `json:"numBackends" yaml:"numBackends"`
+	LastUpdate  string `json:"lastUpdate" yaml:"lastUpdate"`
+	LastError   string `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+}
+
+type publisherView struct {
+	Name        string            `json:"name" yaml:"name"`
+	Type        string            `json:"type" yaml:"type"`
+	MatchLabels map[string]string `json:"matchLabels,omitempty" yaml:"matchLabels,omitempty"`
+}
+
+type applyView struct {
+	Time           string `json:"time" yaml:"time"`
+	Success        bool   `json:"success" yaml:"success"`
+	Error          string `json:"error,omitempty" yaml:"error,omitempty"`
+	ExecutionType  string `json:"executionType" yaml:"executionType"`
+	DurationMillis int64  `json:"durationMillis" yaml:"durationMillis"`
+	NumServices    int32  `json:"numServices" yaml:"numServices"`
+}
+
+func newStatusView(r *localinterface.StatusResponse) statusView {
+	res := statusView{
+		StartTime:     formatUnix(r.StartTime),
+		Uptime:        (time.Duration(r.UptimeSecs) * time.Second).String(),
+		ConfigFile:    r.ConfigFile,
+		ConfigModTime: formatUnix(r.ConfigModTime),
+		Services:      make([]serviceWorkerView, len(r.Services)),
+		Publishers:    make([]publisherView, len(r.Publishers)),
+		Overrides:     make([]overrideView, len(r.Overrides)),
+		Paused:        r.Paused,
+		PausedSince:   formatUnix(r.PausedSince),
+		Pending:       r.PendingChanges,
+	}
+	for idx, sw := range r.Services {
+		res.Services[idx] = serviceWorkerView{
+			Name:        sw.Name,
+			Type:        sw.Type,
+			Address:     sw.Address,
+			Runtime:     sw.Runtime,
+			Source:      sw.Source,
+			NumBackends: sw.NumBackends,
+			LastUpdate:  formatUnix(sw.LastUpdate),
+			LastError:   sw.LastError,
+		}
+	}
+	for idx, p := range r.Publishers {
+		res.Publishers[idx] = publisherView{
+			Name:        p.Name,
+			Type:        p.Type,
+			MatchLabels: p.MatchLabels,
+		}
+	}
+	for idx, o := range r.Overrides {
+		res.Overrides[idx] = overrideView{
+			Service:  o.Service,
+			Address:  o.Address,
+			Weight:   o.Weight,
+			Since:    formatUnix(o.Since),
+			RemoveAt: formatUnix(o.RemoveAt),
+			Removed:  o.Removed,
+		}
+	}
+	if la := r.LastApply; la != nil {
+		res.LastApply = applyView{
+			Time:           formatUnix(la.Time),
+			Success:        la.Success,
+			Error:          la.Error,
+			ExecutionType:  la.ExecutionType,
+			DurationMillis: la.DurationMillis,
+			NumServices:    la.NumServices,
+		}
+	}
+	return res
+}
+
+func printStatusTable(v statusView) {
+	w := newTable()
+	row(w, "Started:", v.StartTime)
+	row(w, "Uptime:", v.Uptime)
+	row(w, "Config file:", v.ConfigFile)
+	row(w, "Config modified:", v.ConfigModTime)
+	if v.LastApply.Time == "" {
+		row(w, "Last apply:", "never")
+	} else {
+		res := "ok"
+		if !v.LastApply.Success {
+			res = fmt.Sprintf("failed: %s", v.LastApply.Error)
+		}
+		row(w, "Last apply:", fmt.Sprintf("%s (%s, %dms, %d services) %s",
+			v.LastApply.Time, v.LastApply.ExecutionType, v.LastApply.DurationMillis, v.LastApply.NumServices, res))
+	}
+	if v.Paused {
+		row(w, "Paused:", fmt.Sprintf("since %s, pending changes: %t", v.PausedSince, v.Pending))
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = newTable()
+	row(w, "SERVICE", "TYPE", "ADDRESS", "BACKENDS", "OWNER", "LAST UPDATE", "ERROR")
+	for _, sw := range v.Services {
+		row(w, sw.Name, sw.Type, sw.Address, sw.NumBackends, owner(sw.Runtime), sw.LastUpdate, sw.LastError)
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = newTable()
+	row(w, "PUBLISHER", "TYPE", "MATCH LABELS")
+	for _, p := range v.Publishers {
+		row(w, p.Name, p.Type, formatLabels(p.MatchLabels))
+	}
+	w.Flush()
+
+	if len(v.Overrides) > 0 {
+		fmt.Println()
+		w = newTable()
+		row(w, "OVERRIDE SERVICE", "BACKEND", "WEIGHT", "SINCE", "REMOVE AT", "REMOVED")
+		for _, o := range v.Overrides {
+			row(w, o.Service, o.Address, o.Weight, o.Since, o.RemoveAt, o.Removed)
+		}
+		w.Flush()
+	}
+}
+
+// formatLabels returns labels as sorted k=v list
+func formatLabels(labels map[string]string) string {
+	res := make([]string, 0, len(labels))
+	for k, v := range labels {
+		res = append(res, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+// DaemonStatus queries the running daemon for its status
+func DaemonStatus(cmd *cli.Cmd) {
+	cmd.Spec = "[-o|--output=<format>]"
+	var (
+		output = cmd.StringOpt("o output", outputTable, "output format: table, json or yaml")
+	)
+
+	cmd.Action = func() {
+		if !isValidOutputFormat(*output) {
+			log.WithField("output", *output).Fatal("Invalid output format.")
+		}
+
+		client, ctx, done := daemonClient()
+		defer done()
+
+		r, err := client.Status(ctx, &localinterface.Empty{})
+		if err != nil {
+			log.WithField("err", err).Fatal("error querying daemon status.")
+		}
+
+		v := newStatusView(r)
+		if *output == outputTable {
+			printStatusTable(v)
+			return
+		}
+		if err := printStructured(*output, v); err != nil {
+			log.WithField("err", err).Fatal("unable to format status.")
+		}
+	}
+}
@@ -19,14 +19,53 @@ func readInput(filename string) ([]byte, error) {
 	return b, err
 }
 
+// ReadModelFromInput reads and parses a configuration file. References
+// to environment variables and secret files are resolved, see Interpolate.
 func ReadModelFromInput(filename string) (*model.IPVSMeshConfig, error) {
-	c := &model.IPVSMeshConfig{}
+	return readModelFromFile(filename, Interpolate)
+}
 
+func readModelFromFile(filename string, interpolate func(*model.IPVSMeshConfig) error) (*model.IPVSMeshConfig, error) {
 	b, err := readInput(filename)
 	if err != nil {
 		return nil, err
 	}
 
+	cfg, err := ReadModelFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := interpolate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadInterpolatedModelFromBytes parses a configuration from yaml like
+// ReadModelFromBytes, and resolves references to environment variables
+// and secret files, see Interpolate.
+func ReadInterpolatedModelFromBytes(b []byte) (*model.IPVSMeshConfig, error) {
+	cfg, err := ReadModelFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := Interpolate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadModelFromBytes parses a configuration from yaml. Configurations
+// of older apiVersions are migrated to the current one.
+func ReadModelFromBytes(b []byte) (*model.IPVSMeshConfig, error) {
+	c := &model.IPVSMeshConfig{}
+
+	b, err := migrateModel(b)
+	if err != nil {
+		log.Errorf("Error parsing yaml")
+		return c, err
+	}
+
 	err = yaml.Unmarshal(b, c)
 	if err != nil {
 		log.Errorf("Error parsing yaml")
@@ -34,3 +73,26 @@ func ReadModelFromInput(filename string) (*model.IPVSMeshConfig, error) {
 
 	return c, err
 }
+
+// ReadServiceFromBytes parses a single service definition from yaml. As
+// with configurations, older definitions are migrated. Services are
+// registered by clients of the daemon, so references to environment
+// variables and secret files are rejected instead of resolved.
+func ReadServiceFromBytes(b []byte) (*model.Service, error) {
+	service := &model.Service{}
+
+	b, err := migrateService(b)
+	if err != nil {
+		log.Errorf("Error parsing yaml")
+		return service, err
+	}
+
+	err = yaml.Unmarshal(b, service)
+	if err != nil {
+		log.Errorf("Error parsing yaml")
+		return service, err
+	}
+
+	err = CheckNoReferences(&model.IPVSMeshConfig{Services: []*model.Service{service}})
+	return service, err
+}
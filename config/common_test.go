@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestReadServiceFromBytes(t *testing.T) {
+	b := []byte(`name: web
+type: proxyFromFile
+spec:
+  address: 10.0.0.1:80
+  file: /tmp/backends.txt
+`)
+	service, err := ReadServiceFromBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Address != "10.0.0.1:80" {
+		t.Errorf("got address %q, expected spec address to be migrated", service.Address)
+	}
+	spec := service.Spec
+	if _, ex := spec["address"]; ex {
+		t.Error("spec address has not been removed")
+	}
+	if spec["file"] != "/tmp/backends.txt" {
+		t.Errorf("got file %v", spec["file"])
+	}
+}
+
+func TestReadServiceFromBytesRejectsReferences(t *testing.T) {
+	os.Setenv("IPVSMESH_TEST_FILE", "/tmp/backends.txt")
+	defer os.Unsetenv("IPVSMESH_TEST_FILE")
+
+	for _, ref := range []string{"${env:IPVSMESH_TEST_FILE}", "${file:/etc/hostname}", "x-${env:HOME}"} {
+		_, err := ReadServiceFromBytes([]byte("name: web\nspec:\n  file: " + ref + "\n"))
+		if err == nil {
+			t.Errorf("%s: expected an error", ref)
+		}
+	}
+}
@@ -11,14 +11,28 @@ type Configuration struct {
 	Verbose               bool   `env:"IPVSMESH_LOG_VERBOSE" envDefault:"false"`
 	DaemonizeFlag         bool   `env:"IPVSMESH_DAEMONIZE" envDefault:"false"`
 	DaemonSocketPath      string `env:"IPVSMESH_SOCKET" envDefault:"/tmp/ipvsmesh.sock"`
+	DaemonPidFile         string `env:"IPVSMESH_PIDFILE" envDefault:""` // default: derived from socket path
 	DaemonConnTimeoutSecs int    `env:"IPVSMESH_DAEMON_TIMEOUT_SEC" envDefault:"5"`
+	DaemonListenAddress   string `env:"IPVSMESH_LISTEN" envDefault:""` // optional TCP address of daemon, e.g. :7443
+	DaemonHost            string `env:"IPVSMESH_HOST" envDefault:""`   // TCP address of a remote daemon to connect to
+	HTTPListenAddress     string `env:"IPVSMESH_HTTP" envDefault:""`   // optional address of health endpoints, e.g. :8080
 
 	DefaultConfigFile string `env:"IPVSMESH_CONFIGFILE" envDefault:"/etc/ipvsmesh.yaml"`
 	DefaultTimeout    int    `env:"IPVSMESH_SVCTIMEOUT" envDefault:"10"`
+	StateFile         string `env:"IPVSMESH_STATEFILE" envDefault:""`
+
+	// remote configuration, if DefaultConfigFile is a http(s) URL
+	ConfigCAFile    string `env:"IPVSMESH_CONFIG_CAFILE" envDefault:""`    // CA to verify the config server with
+	ConfigCacheFile string `env:"IPVSMESH_CONFIG_CACHEFILE" envDefault:""` // default: no cache
+	ConfigPollSecs  int    `env:"IPVSMESH_CONFIG_POLL_SEC" envDefault:"30"`
 
 	TLS         bool   `env:"IPVSMESH_TLS" envDefault:"false"`
 	TLSCertFile string `env:"IPVSMESH_TLSCERTFILE" envDefault:""`
 	TLSKeyFile  string `env:"IPVSMESH_TLSKEYFILE" envDefault:""`
+
+	// CA to verify the peer with: clients by the daemon, the daemon by clients
+	TLSCAFile     string `env:"IPVSMESH_TLSCAFILE" envDefault:""`
+	TLSServerName string `env:"IPVSMESH_TLSSERVERNAME" envDefault:"ipvsmesh-grpc-tls-comm"`
 }
 
 var (
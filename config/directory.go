@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// ReadModel reads a configuration from path. If path is a directory, all
+// *.yaml files within it are read and merged, see ReadModelFromDirectory.
+// Services and publishers reference the file they have been read from.
+func ReadModel(path string) (*model.IPVSMeshConfig, error) {
+	return readModel(path, Interpolate)
+}
+
+// ReadMaskedModel reads a configuration from path like ReadModel, but
+// replaces secrets by a placeholder, see InterpolateMasked.
+func ReadMaskedModel(path string) (*model.IPVSMeshConfig, error) {
+	return readModel(path, InterpolateMasked)
+}
+
+func readModel(path string, interpolate func(*model.IPVSMeshConfig) error) (*model.IPVSMeshConfig, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return readModelFromDirectory(path, interpolate)
+	}
+
+	cfg, err := readModelFromFile(path, interpolate)
+	if err != nil {
+		return nil, err
+	}
+	setSource(cfg, path)
+	return cfg, nil
+}
+
+// ConfigFiles returns the configuration files at path. This is path itself,
+// or all *.yaml files in lexical order if path is a directory.
+func ConfigFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// LastModified returns the most recent modification time of path and,
+// for directories, all configuration files within it.
+func LastModified(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	res := info.ModTime()
+
+	files, err := ConfigFiles(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(res) {
+			res = info.ModTime()
+		}
+	}
+	return res, nil
+}
+
+// ReadModelFromDirectory reads all *.yaml files of dir in lexical order and
+// merges them into a single configuration. Parameters and globals are merged
+// key by key, where later files take precedence over earlier ones. Services
+// and publishers are collected from all files, their names must be unique.
+func ReadModelFromDirectory(dir string) (*model.IPVSMeshConfig, error) {
+	return readModelFromDirectory(dir, Interpolate)
+}
+
+func readModelFromDirectory(dir string, interpolate func(*model.IPVSMeshConfig) error) (*model.IPVSMeshConfig, error) {
+	files, err := ConfigFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &model.IPVSMeshConfig{}
+	var errs Errors
+	for _, file := range files {
+		cfg, err := readModelFromFile(file, interpolate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", file, err))
+			continue
+		}
+		setSource(cfg, file)
+
+		errs = append(errs, mergeModel(res, cfg)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return res, nil
+}
+
+// setSource records file as source of all services and publishers of cfg
+func setSource(cfg *model.IPVSMeshConfig, file string) {
+	for _, service := range cfg.Services {
+		service.Source = file
+	}
+	for _, publisher := range cfg.Publishers {
+		publisher.Source = file
+	}
+}
+
+// mergeModel merges src into dst. It returns an error for each
+// service or publisher of src whose name is already taken in dst.
+func mergeModel(dst, src *model.IPVSMeshConfig) Errors {
+	var errs Errors
+
+	dst.APIVersion = src.APIVersion
+
+	if len(src.Parameters) > 0 && dst.Parameters == nil {
+		dst.Parameters = make(map[string]model.Parameter)
+	}
+	for name, p := range src.Parameters {
+		dst.Parameters[name] = p
+	}
+
+	mergeGlobals(&dst.Globals, &src.Globals)
+
+	for _, service := range src.Services {
+		if other := findService(dst.Services, service.Name); other != nil {
+			errs = append(errs, fmt.Errorf("duplicate service %s in %s, already defined in %s", service.Name, service.Source, other.Source))
+			continue
+		}
+		dst.Services = append(dst.Services, service)
+	}
+	for _, publisher := range src.Publishers {
+		if other := findPublisher(dst.Publishers, publisher.Name); other != nil {
+			errs = append(errs, fmt.Errorf("duplicate publisher %s in %s, already defined in %s", publisher.Name, publisher.Source, other.Source))
+			continue
+		}
+		dst.Publishers = append(dst.Publishers, publisher)
+	}
+
+	return errs
+}
+
+// mergeGlobals sets all fields and map entries given in src on dst
+func mergeGlobals(dst, src *model.Globals) {
+	if src.Ipvsctl.ExecType != "" {
+		dst.Ipvsctl.ExecType = src.Ipvsctl.ExecType
+	}
+	if src.Ipvsctl.Filename != "" {
+		dst.Ipvsctl.Filename = src.Ipvsctl.Filename
+	}
+	if src.Ipvsctl.IpvsctlPath != "" {
+		dst.Ipvsctl.IpvsctlPath = src.Ipvsctl.IpvsctlPath
+	}
+
+	if len(src.Config) > 0 && dst.Config == nil {
+		dst.Config = make(map[string]model.ConfigProfile)
+	}
+	for name, p := range src.Config {
+		dst.Config[name] = p
+	}
+
+	if len(src.Settings) > 0 && dst.Settings == nil {
+		dst.Settings = make(map[string]string)
+	}
+	for k, v := range src.Settings {
+		dst.Settings[k] = v
+	}
+
+	if src.Shutdown.Policy != "" {
+		dst.Shutdown.Policy = src.Shutdown.Policy
+	}
+	if src.Shutdown.DrainPeriod != "" {
+		dst.Shutdown.DrainPeriod = src.Shutdown.DrainPeriod
+	}
+
+	dst.Defaults.ServiceOptions = src.Defaults.ServiceOptions.WithDefaults(dst.Defaults.ServiceOptions)
+	if len(src.Defaults.Types) > 0 && dst.Defaults.Types == nil {
+		dst.Defaults.Types = make(map[string]model.ServiceOptions)
+	}
+	for serviceType, o := range src.Defaults.Types {
+		dst.Defaults.Types[serviceType] = o.WithDefaults(dst.Defaults.Types[serviceType])
+	}
+}
+
+func findService(services []*model.Service, name string) *model.Service {
+	for _, service := range services {
+		if service.Name == name {
+			return service
+		}
+	}
+	return nil
+}
+
+func findPublisher(publishers []*model.Publisher, name string) *model.Publisher {
+	for _, publisher := range publishers {
+		if publisher.Name == name {
+			return publisher
+		}
+	}
+	return nil
+}
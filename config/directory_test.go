@@ -0,0 +1,108 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+func TestMergeModel(t *testing.T) {
+	dst := &model.IPVSMeshConfig{
+		Parameters: map[string]model.Parameter{"a": {}},
+		Services: []*model.Service{
+			{Name: "web", Source: "10-web.yaml"},
+		},
+		Publishers: []*model.Publisher{
+			{Name: "pub", Source: "10-web.yaml"},
+		},
+	}
+	src := &model.IPVSMeshConfig{
+		APIVersion: model.CurrentAPIVersion,
+		Parameters: map[string]model.Parameter{"b": {}},
+		Services: []*model.Service{
+			{Name: "web", Source: "20-more.yaml"},
+			{Name: "dns", Source: "20-more.yaml"},
+		},
+		Publishers: []*model.Publisher{
+			{Name: "pub", Source: "20-more.yaml"},
+		},
+	}
+
+	errs := mergeModel(dst, src)
+	expectedErrs := []string{
+		"duplicate service web in 20-more.yaml, already defined in 10-web.yaml",
+		"duplicate publisher pub in 20-more.yaml, already defined in 10-web.yaml",
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("got errors %v, expected %v", errs, expectedErrs)
+	}
+	for idx, err := range errs {
+		if err.Error() != expectedErrs[idx] {
+			t.Errorf("got error %q, expected %q", err, expectedErrs[idx])
+		}
+	}
+
+	var names []string
+	for _, service := range dst.Services {
+		names = append(names, service.Name+"@"+service.Source)
+	}
+	if expected := []string{"web@10-web.yaml", "dns@20-more.yaml"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got services %v, expected %v", names, expected)
+	}
+	if len(dst.Publishers) != 1 || dst.Publishers[0].Source != "10-web.yaml" {
+		t.Errorf("duplicate publisher has been merged: %+v", dst.Publishers)
+	}
+	if len(dst.Parameters) != 2 {
+		t.Errorf("got parameters %v, expected a and b", dst.Parameters)
+	}
+	if dst.APIVersion != model.CurrentAPIVersion {
+		t.Errorf("got apiVersion %q", dst.APIVersion)
+	}
+}
+
+func TestMergeGlobals(t *testing.T) {
+	dst := model.Globals{
+		Ipvsctl:  model.IpvsctlConfig{ExecType: "file-only", Filename: "/tmp/first.yaml"},
+		Settings: map[string]string{"a": "1", "b": "1"},
+		Shutdown: model.ShutdownConfig{Policy: model.ShutdownDrain, DrainPeriod: "30s"},
+		Defaults: model.Defaults{
+			ServiceOptions: model.ServiceOptions{SchedName: "rr", Weight: 10},
+			Types: map[string]model.ServiceOptions{
+				"dockerFrontProxy": {Forward: "direct"},
+			},
+		},
+	}
+	src := model.Globals{
+		Ipvsctl:  model.IpvsctlConfig{Filename: "/tmp/second.yaml"},
+		Config:   map[string]model.ConfigProfile{"docker": {Type: "docker"}},
+		Settings: map[string]string{"b": "2"},
+		Shutdown: model.ShutdownConfig{Policy: model.ShutdownFlush},
+		Defaults: model.Defaults{
+			ServiceOptions: model.ServiceOptions{Weight: 20},
+			Types: map[string]model.ServiceOptions{
+				"dockerFrontProxy": {Weight: 5},
+				"proxyFromFile":    {SchedName: "lc"},
+			},
+		},
+	}
+
+	mergeGlobals(&dst, &src)
+
+	expected := model.Globals{
+		Ipvsctl:  model.IpvsctlConfig{ExecType: "file-only", Filename: "/tmp/second.yaml"},
+		Config:   map[string]model.ConfigProfile{"docker": {Type: "docker"}},
+		Settings: map[string]string{"a": "1", "b": "2"},
+		Shutdown: model.ShutdownConfig{Policy: model.ShutdownFlush, DrainPeriod: "30s"},
+		Defaults: model.Defaults{
+			ServiceOptions: model.ServiceOptions{SchedName: "rr", Weight: 20},
+			Types: map[string]model.ServiceOptions{
+				"dockerFrontProxy": {Forward: "direct", Weight: 5},
+				"proxyFromFile":    {SchedName: "lc"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("got %+v, expected %+v", dst, expected)
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"strings"
+)
+
+// Errors collects all problems found within a configuration, so
+// that they can be reported at once instead of one by one.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for idx, err := range e {
+		msgs[idx] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/aschmidt75/ipvsmesh/logging"
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// Kinds of references
+const (
+	refEnv       = "env"       // value of an environment variable
+	refSecretEnv = "secretEnv" // value of an environment variable, which is redacted
+	refFile      = "file"      // content of a file, which is redacted
+)
+
+// secretRefRegexp matches references such as ${env:NAME} or ${file:/run/secrets/x}
+var secretRefRegexp = regexp.MustCompile(`\$\{(env|secretEnv|file):([^}]+)\}`)
+
+// Interpolate replaces ${env:NAME} and ${secretEnv:NAME} references by the
+// value of environment variables and ${file:/path} references by the contents
+// of files, within globals, service specs and publisher specs of cfg. Values of
+// ${secretEnv:...} and ${file:...} are secrets and redacted from all log output.
+// Only use this for configuration from trusted sources, as it reads arbitrary
+// files.
+func Interpolate(cfg *model.IPVSMeshConfig) error {
+	return walkReferences(cfg, func(s string) (string, error) {
+		return interpolateString(s, false)
+	})
+}
+
+// InterpolateMasked replaces references like Interpolate, but replaces
+// secrets by a placeholder instead of their value, for printing cfg.
+// Secret files are not read.
+func InterpolateMasked(cfg *model.IPVSMeshConfig) error {
+	return walkReferences(cfg, func(s string) (string, error) {
+		return interpolateString(s, true)
+	})
+}
+
+// CheckNoReferences returns an error for all ${env:...}, ${secretEnv:...} and
+// ${file:...} references within cfg. Configurations from untrusted sources,
+// such as requests to the daemon, must not contain references, as resolving
+// them would expose the environment and files of the daemon.
+func CheckNoReferences(cfg *model.IPVSMeshConfig) error {
+	return walkReferences(cfg, func(s string) (string, error) {
+		if ref := secretRefRegexp.FindString(s); ref != "" {
+			return s, fmt.Errorf("reference %s is not allowed here", ref)
+		}
+		return s, nil
+	})
+}
+
+// walkReferences calls f for all strings within globals, service specs and
+// publisher specs of cfg, which may contain references, and replaces them
+// by the result.
+func walkReferences(cfg *model.IPVSMeshConfig, f func(string) (string, error)) error {
+	var errs Errors
+
+	if err := walkStrings(reflect.ValueOf(&cfg.Globals).Elem(), f); err != nil {
+		errs = append(errs, fmt.Errorf("globals: %s", err))
+	}
+	for _, service := range cfg.Services {
+		if err := walkStrings(reflect.ValueOf(&service.Spec).Elem(), f); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %s", service.Name, err))
+		}
+	}
+	for _, publisher := range cfg.Publishers {
+		if err := walkStrings(reflect.ValueOf(&publisher.Spec).Elem(), f); err != nil {
+			errs = append(errs, fmt.Errorf("publisher %s: %s", publisher.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// interpolateString replaces all references within s. Secrets are
+// registered for redaction, or replaced by a placeholder if mask is set.
+func interpolateString(s string, mask bool) (string, error) {
+	var errs Errors
+
+	res := secretRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		m := secretRefRegexp.FindStringSubmatch(ref)
+
+		if mask && m[1] != refEnv {
+			return logging.RedactedValue
+		}
+
+		var value string
+		switch m[1] {
+		case refEnv, refSecretEnv:
+			v, ex := os.LookupEnv(m[2])
+			if !ex {
+				errs = append(errs, fmt.Errorf("environment variable %s is not set", m[2]))
+				return ref
+			}
+			value = v
+		case refFile:
+			b, err := ioutil.ReadFile(m[2])
+			if err != nil {
+				errs = append(errs, err)
+				return ref
+			}
+			value = strings.TrimRight(string(b), "\r\n")
+		}
+
+		if m[1] != refEnv {
+			logging.AddSecret(value)
+		}
+		return value
+	})
+
+	if len(errs) > 0 {
+		return s, errs
+	}
+	return res, nil
+}
+
+// walkStrings replaces all strings reachable from v, which must be
+// settable, by the result of f. Map keys are not replaced.
+func walkStrings(v reflect.Value, f func(string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := f(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return walkStrings(v.Elem(), f)
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		if err := walkStrings(e, f); err != nil {
+			return err
+		}
+		v.Set(e)
+
+	case reflect.Struct:
+		var errs Errors
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// unexported
+				continue
+			}
+			if err := walkStrings(v.Field(i), f); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+
+	case reflect.Map:
+		var errs Errors
+		for _, k := range v.MapKeys() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(v.MapIndex(k))
+			if err := walkStrings(e, f); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			v.SetMapIndex(k, e)
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+
+	case reflect.Slice:
+		var errs Errors
+		for i := 0; i < v.Len(); i++ {
+			if err := walkStrings(v.Index(i), f); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+	return nil
+}
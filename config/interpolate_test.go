@@ -0,0 +1,107 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/logging"
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+func TestInterpolate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipvsmesh-interpolate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(secretFile, []byte("fil3-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("IPVSMESH_TEST_HOST", "dockerhost.example")
+	os.Setenv("IPVSMESH_TEST_TOKEN", "t0k")
+	defer os.Unsetenv("IPVSMESH_TEST_HOST")
+	defer os.Unsetenv("IPVSMESH_TEST_TOKEN")
+
+	spec := func() map[interface{}]interface{} {
+		return map[interface{}]interface{}{
+			"host":     "tcp://${env:IPVSMESH_TEST_HOST}:2375",
+			"token":    "${secretEnv:IPVSMESH_TEST_TOKEN}",
+			"password": "${file:" + secretFile + "}",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		interpolate func(*model.IPVSMeshConfig) error
+		expected    map[interface{}]interface{}
+	}{
+		{
+			name:        "resolved",
+			interpolate: Interpolate,
+			expected: map[interface{}]interface{}{
+				"host":     "tcp://dockerhost.example:2375",
+				"token":    "t0k",
+				"password": "fil3-secret",
+			},
+		},
+		{
+			name:        "masked",
+			interpolate: InterpolateMasked,
+			expected: map[interface{}]interface{}{
+				"host":     "tcp://dockerhost.example:2375",
+				"token":    logging.RedactedValue,
+				"password": logging.RedactedValue,
+			},
+		},
+	}
+	for _, tt := range tests {
+		cfg := &model.IPVSMeshConfig{Services: []*model.Service{{Name: "web", Spec: spec()}}}
+		if err := tt.interpolate(cfg); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		for k, v := range tt.expected {
+			if cfg.Services[0].Spec[k] != v {
+				t.Errorf("%s: got %s %q, expected %q", tt.name, k, cfg.Services[0].Spec[k], v)
+			}
+		}
+	}
+
+	// only secrets are redacted from output
+	out := string(logging.Redact([]byte("dockerhost.example t0k fil3-secret")))
+	if expected := "dockerhost.example ****** ******"; out != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+
+	cfg := &model.IPVSMeshConfig{Globals: model.Globals{Settings: map[string]string{"x": "${env:IPVSMESH_TEST_UNSET}"}}}
+	if err := Interpolate(cfg); err == nil {
+		t.Error("expected an error for an unset variable")
+	}
+}
+
+func TestCheckNoReferences(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"plain", true},
+		{"${host.eth0}", true},
+		{"${env:HOME}", false},
+		{"x-${secretEnv:TOKEN}", false},
+		{"${file:/etc/shadow}", false},
+	}
+	for _, tt := range tests {
+		cfg := &model.IPVSMeshConfig{Publishers: []*model.Publisher{
+			{Name: "pub", Spec: map[interface{}]interface{}{"list": []interface{}{tt.value}}},
+		}}
+		err := CheckNoReferences(cfg)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %s", tt.value, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.value)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// migration converts a configuration document of one apiVersion
+// into the next version, in place.
+type migration struct {
+	to      string
+	migrate func(doc *yamlv3.Node) error
+}
+
+// migrations by the apiVersion they convert from
+var migrations = map[string]migration{
+	model.APIVersionV1: {to: model.APIVersionV2, migrate: migrateV1},
+}
+
+// apiVersionOf returns the apiVersion of a configuration. Configurations
+// without apiVersion are of APIVersionV1.
+func apiVersionOf(b []byte) string {
+	v := struct {
+		APIVersion string `yaml:"apiVersion"`
+	}{}
+	// errors are reported when decoding the configuration
+	yaml.Unmarshal(b, &v)
+
+	if v.APIVersion == "" {
+		return model.APIVersionV1
+	}
+	return v.APIVersion
+}
+
+// MigrateBytes converts a configuration document to the current apiVersion.
+// It returns the converted document and the version it has been converted
+// from. Documents of the current version are returned unchanged.
+func MigrateBytes(b []byte) ([]byte, string, error) {
+	from := apiVersionOf(b)
+	if from == model.CurrentAPIVersion {
+		return b, from, nil
+	}
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(b, &root); err != nil {
+		return nil, from, err
+	}
+	if len(root.Content) == 0 {
+		return b, from, nil
+	}
+	if _, err := migrateDocument(root.Content[0]); err != nil {
+		return nil, from, err
+	}
+
+	res, err := encodeDocument(&root)
+	return res, from, err
+}
+
+// encodeDocument encodes a yaml document node as the configuration
+// files are indented
+func encodeDocument(root *yamlv3.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MigrateFile converts a configuration file to the current apiVersion in
+// place. It returns the version the file has been converted from. Files of
+// the current version are not touched.
+func MigrateFile(filename string) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	res, from, err := MigrateBytes(b)
+	if err != nil {
+		return from, err
+	}
+	if from == model.CurrentAPIVersion {
+		return from, nil
+	}
+	return from, writeFileAtomic(filename, res, info.Mode().Perm())
+}
+
+// migrateDocument converts a document node to the current apiVersion
+// in place. Positions of existing nodes are kept. It returns the
+// version the document has been converted from.
+func migrateDocument(doc *yamlv3.Node) (string, error) {
+	if doc.Kind != yamlv3.MappingNode {
+		return "", fmt.Errorf("configuration is not a mapping")
+	}
+
+	from := model.APIVersionV1
+	versionNode := mappingValue(doc, "apiVersion")
+	if versionNode != nil {
+		from = versionNode.Value
+	}
+
+	for version := from; version != model.CurrentAPIVersion; {
+		m, ex := migrations[version]
+		if !ex {
+			return from, fmt.Errorf("unsupported apiVersion %s", version)
+		}
+		if err := m.migrate(doc); err != nil {
+			return from, fmt.Errorf("unable to migrate from %s to %s: %s", version, m.to, err)
+		}
+		version = m.to
+	}
+
+	if versionNode != nil {
+		versionNode.Value = model.CurrentAPIVersion
+	} else if from != model.CurrentAPIVersion {
+		doc.Content = append([]*yamlv3.Node{
+			{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: "apiVersion"},
+			{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: model.CurrentAPIVersion},
+		}, doc.Content...)
+	}
+	return from, nil
+}
+
+// migrateV1 moves service addresses from spec to service level
+func migrateV1(doc *yamlv3.Node) error {
+	services := mappingValue(doc, "services")
+	if services == nil || services.Kind != yamlv3.SequenceNode {
+		return nil
+	}
+	for _, service := range services.Content {
+		if err := migrateServiceV1(service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateServiceV1 moves the address of a single service from spec to service level
+func migrateServiceV1(service *yamlv3.Node) error {
+	spec := mappingValue(service, "spec")
+	if spec == nil || spec.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	idx := mappingIndex(spec, "address")
+	if idx < 0 {
+		return nil
+	}
+	key, value := spec.Content[idx], spec.Content[idx+1]
+	spec.Content = append(spec.Content[:idx:idx], spec.Content[idx+2:]...)
+
+	if existing := mappingValue(service, "address"); existing != nil {
+		if existing.Value != value.Value {
+			return fmt.Errorf("line %d: service has address %s and spec address %s", value.Line, existing.Value, value.Value)
+		}
+		return nil
+	}
+
+	// place address in front of spec
+	specIdx := mappingIndex(service, "spec")
+	content := make([]*yamlv3.Node, 0, len(service.Content)+2)
+	content = append(content, service.Content[:specIdx]...)
+	content = append(content, key, value)
+	content = append(content, service.Content[specIdx:]...)
+	service.Content = content
+	return nil
+}
+
+// mappingIndex returns the index of the key node of key within
+// a mapping node, or -1 if not found
+func mappingIndex(node *yamlv3.Node, key string) int {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// migrateModel converts a configuration of an older apiVersion before it is decoded
+func migrateModel(b []byte) ([]byte, error) {
+	res, from, err := MigrateBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if from != model.CurrentAPIVersion {
+		log.WithFields(log.Fields{
+			"from": from,
+			"to":   model.CurrentAPIVersion,
+		}).Info("config: Migrated configuration, use ipvsmesh config migrate to update it")
+	}
+	return res, nil
+}
+
+// migrateService converts a single service definition to the current
+// apiVersion. Service definitions carry no apiVersion, so all migrations
+// of services are applied; they do not change current definitions.
+func migrateService(b []byte) ([]byte, error) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yamlv3.MappingNode {
+		// errors are reported when decoding the service
+		return b, nil
+	}
+	if err := migrateServiceV1(root.Content[0]); err != nil {
+		return nil, err
+	}
+	return encodeDocument(&root)
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+func TestMigrateBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		from     string
+		expected string
+		err      string
+	}{
+		{
+			name: "current version is unchanged",
+			in: `apiVersion: ipvsmesh/v2
+services:
+  - name: web
+    address: tcp://10.0.0.1:80
+`,
+			from: model.APIVersionV2,
+			expected: `apiVersion: ipvsmesh/v2
+services:
+  - name: web
+    address: tcp://10.0.0.1:80
+`,
+		},
+		{
+			name: "v1 spec address moves to service",
+			in: `services:
+  - name: web
+    type: proxyFromFile
+    spec:
+      address: tcp://10.0.0.1:80
+      file: backends.txt
+`,
+			from: model.APIVersionV1,
+			expected: `apiVersion: ipvsmesh/v2
+services:
+- name: web
+  type: proxyFromFile
+  address: tcp://10.0.0.1:80
+  spec:
+    file: backends.txt
+`,
+		},
+		{
+			name: "v1 with equal addresses",
+			in: `apiVersion: ipvsmesh/v1
+services:
+  - name: web
+    address: tcp://10.0.0.1:80
+    spec:
+      address: tcp://10.0.0.1:80
+`,
+			from: model.APIVersionV1,
+			expected: `apiVersion: ipvsmesh/v2
+services:
+- name: web
+  address: tcp://10.0.0.1:80
+  spec: {}
+`,
+		},
+		{
+			name: "v1 with conflicting addresses",
+			in: `apiVersion: ipvsmesh/v1
+services:
+  - name: web
+    address: tcp://10.0.0.1:80
+    spec:
+      address: tcp://10.0.0.2:80
+`,
+			from: model.APIVersionV1,
+			err:  "line 6: service has address tcp://10.0.0.1:80 and spec address tcp://10.0.0.2:80",
+		},
+		{
+			name: "unsupported version",
+			in:   "apiVersion: ipvsmesh/v0\n",
+			from: "ipvsmesh/v0",
+			err:  "unsupported apiVersion ipvsmesh/v0",
+		},
+	}
+	for _, tt := range tests {
+		res, from, err := MigrateBytes([]byte(tt.in))
+		if from != tt.from {
+			t.Errorf("%s: got version %q, expected %q", tt.name, from, tt.from)
+		}
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got error %v, expected %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.name, err)
+			continue
+		}
+		if string(res) != tt.expected {
+			t.Errorf("%s: got\n%s\nexpected\n%s", tt.name, res, tt.expected)
+		}
+	}
+}
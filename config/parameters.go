@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"regexp"
+
+	"github.com/aschmidt75/ipvsmesh/logging"
+	"github.com/aschmidt75/ipvsmesh/model"
+	"gopkg.in/yaml.v2"
+)
+
+// Types of parameter sources
+const (
+	ParameterTypeLocalNetwork = "localNetwork" // addresses of local interfaces, e.g. ${host.eth0}
+	ParameterTypeEnv          = "env"          // environment variables, e.g. ${env.HOME}
+	ParameterTypeFile         = "file"         // keys of a yaml file, e.g. ${portmap.PORT}
+)
+
+// parameterRefRegexp matches references such as ${host.eth0}
+var parameterRefRegexp = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)\.([^}]+)\}`)
+
+// resolvedParameter contains the values of a parameter by key
+type resolvedParameter struct {
+	values map[string]string // nil for env, which is looked up on use
+	secret bool
+}
+
+// ParameterValues contains resolved values of all parameters
+type ParameterValues struct {
+	params map[string]resolvedParameter // by name
+	mask   bool                         // replace values of secret parameters by a placeholder
+}
+
+// UsesLocalNetwork returns true if any parameter of cfg resolves
+// addresses of local interfaces
+func UsesLocalNetwork(cfg *model.IPVSMeshConfig) bool {
+	for _, p := range cfg.Parameters {
+		if p.Type == ParameterTypeLocalNetwork {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalNetworkAddresses returns the address of all local interfaces
+// that are up, by interface name. IPv4 addresses are preferred.
+func LocalNetworkAddresses() (map[string]string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if ip4 := ipnet.IP.To4(); ip4 != nil {
+				res[iface.Name] = ip4.String()
+				break
+			}
+			if _, ex := res[iface.Name]; !ex {
+				res[iface.Name] = ipnet.IP.String()
+			}
+		}
+	}
+	return res, nil
+}
+
+// ResolveParameters resolves the values of all given parameters. If some
+// cannot be resolved, the values of all others are returned together
+// with the errors.
+func ResolveParameters(params map[string]model.Parameter) (ParameterValues, error) {
+	var errs Errors
+
+	res := ParameterValues{params: make(map[string]resolvedParameter, len(params))}
+	for name, p := range params {
+		var values map[string]string
+		var err error
+
+		switch p.Type {
+		case ParameterTypeLocalNetwork:
+			values, err = LocalNetworkAddresses()
+		case ParameterTypeEnv:
+			// looked up on use, not to copy the whole environment
+		case ParameterTypeFile:
+			values, err = fileValues(p.File)
+		default:
+			err = fmt.Errorf("unknown type %s", p.Type)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to resolve parameter %s: %s", name, err))
+			continue
+		}
+		res.params[name] = resolvedParameter{values: values, secret: p.Secret}
+	}
+
+	if len(errs) > 0 {
+		return res, errs
+	}
+	return res, nil
+}
+
+// lookup returns the value of key of parameter name
+func (v ParameterValues) lookup(name, key string) (string, bool) {
+	p, ex := v.params[name]
+	if !ex {
+		return "", false
+	}
+	if p.values == nil {
+		return os.LookupEnv(key)
+	}
+	value, ex := p.values[key]
+	return value, ex
+}
+
+// fileValues reads keys and values from a yaml file
+func fileValues(filename string) (map[string]string, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("missing file")
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("value of %s is not a scalar", k)
+		}
+		res[k] = fmt.Sprintf("%v", v)
+	}
+	return res, nil
+}
+
+// Expand replaces all parameter references in s. Unresolved
+// references are returned as error. Values of secret parameters
+// are redacted from log output.
+func (v ParameterValues) Expand(s string) (string, error) {
+	var errs Errors
+
+	res := parameterRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		m := parameterRefRegexp.FindStringSubmatch(ref)
+		value, ex := v.lookup(m[1], m[2])
+		if !ex {
+			errs = append(errs, fmt.Errorf("unresolved parameter %s", ref))
+			return ref
+		}
+		if v.params[m[1]].secret {
+			if v.mask {
+				return logging.RedactedValue
+			}
+			logging.AddSecret(value)
+		}
+		return value
+	})
+
+	if len(errs) > 0 {
+		return s, errs
+	}
+	return res, nil
+}
+
+// expandSpec replaces parameter references in all string values of a spec
+func (v ParameterValues) expandSpec(spec interface{}) (interface{}, error) {
+	switch t := spec.(type) {
+	case string:
+		return v.Expand(t)
+	case map[interface{}]interface{}:
+		var errs Errors
+		for k, e := range t {
+			ex, err := v.expandSpec(e)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			t[k] = ex
+		}
+		if len(errs) > 0 {
+			return t, errs
+		}
+		return t, nil
+	case []interface{}:
+		var errs Errors
+		for idx, e := range t {
+			ex, err := v.expandSpec(e)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			t[idx] = ex
+		}
+		if len(errs) > 0 {
+			return t, errs
+		}
+		return t, nil
+	default:
+		return spec, nil
+	}
+}
+
+// ExpandParameters resolves the parameters of cfg and replaces all references
+// to them within service addresses, service specs and publisher specs.
+// All problems found are returned as Errors. Parameters read files and the
+// environment, only use this for configuration from trusted sources.
+func ExpandParameters(cfg *model.IPVSMeshConfig) error {
+	return expandParameters(cfg, false)
+}
+
+// ExpandMaskedParameters replaces parameter references like ExpandParameters,
+// but replaces values of secret parameters by a placeholder, for printing cfg.
+func ExpandMaskedParameters(cfg *model.IPVSMeshConfig) error {
+	return expandParameters(cfg, true)
+}
+
+func expandParameters(cfg *model.IPVSMeshConfig, mask bool) error {
+	values, err := ResolveParameters(cfg.Parameters)
+	if err != nil {
+		return err
+	}
+	values.mask = mask
+
+	var errs Errors
+	for _, service := range cfg.Services {
+		if err := values.expandService(service); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %s", service.Name, err))
+		}
+	}
+	for _, publisher := range cfg.Publishers {
+		if err := values.expandPublisher(publisher); err != nil {
+			errs = append(errs, fmt.Errorf("publisher %s: %s", publisher.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// expandService replaces parameter references in address and spec of a service
+func (v ParameterValues) expandService(service *model.Service) error {
+	var errs Errors
+
+	address, err := v.Expand(service.Address)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	service.Address = address
+
+	if _, err := v.expandSpec(service.Spec); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// expandPublisher replaces parameter references in the spec of a publisher
+func (v ParameterValues) expandPublisher(publisher *model.Publisher) error {
+	_, err := v.expandSpec(publisher.Spec)
+	return err
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/logging"
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// testParameterValues returns values of a file parameter portmap,
+// a secret file parameter creds and an env parameter env
+func testParameterValues() ParameterValues {
+	return ParameterValues{params: map[string]resolvedParameter{
+		"portmap": {values: map[string]string{"HTTP": "8080", "DNS": "53"}},
+		"creds":   {values: map[string]string{"password": "pa55"}, secret: true},
+		"env":     {},
+	}}
+}
+
+func TestExpand(t *testing.T) {
+	os.Setenv("IPVSMESH_TEST_ADDR", "10.1.0.1")
+	defer os.Unsetenv("IPVSMESH_TEST_ADDR")
+
+	tests := []struct {
+		in       string
+		mask     bool
+		expected string
+		valid    bool
+	}{
+		{"tcp://10.0.0.1:80", false, "tcp://10.0.0.1:80", true},
+		{"tcp://10.0.0.1:${portmap.HTTP}", false, "tcp://10.0.0.1:8080", true},
+		{"tcp://${env.IPVSMESH_TEST_ADDR}:${portmap.HTTP}", false, "tcp://10.1.0.1:8080", true},
+		{"udp://${env.IPVSMESH_TEST_ADDR}:${portmap.DNS}", true, "udp://10.1.0.1:53", true},
+		{"${creds.password}", false, "pa55", true},
+		{"${creds.password}", true, logging.RedactedValue, true},
+		{"tcp://10.0.0.1:${portmap.SSH}", false, "", false},
+		{"${env.IPVSMESH_TEST_UNSET}", false, "", false},
+		{"${unknown.x}", false, "", false},
+	}
+	for _, tt := range tests {
+		v := testParameterValues()
+		v.mask = tt.mask
+		res, err := v.Expand(tt.in)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.in, err)
+			continue
+		}
+		if res != tt.expected {
+			t.Errorf("%s: got %q, expected %q", tt.in, res, tt.expected)
+		}
+	}
+
+	// values of secret parameters are redacted
+	if out := string(logging.Redact([]byte("password pa55"))); out != "password ******" {
+		t.Errorf("secret parameter has not been redacted: %q", out)
+	}
+}
+
+func TestExpandSpec(t *testing.T) {
+	spec := map[interface{}]interface{}{
+		"port":  "${portmap.HTTP}",
+		"count": 3,
+		"nested": map[interface{}]interface{}{
+			"list": []interface{}{"${portmap.DNS}", "plain", true},
+		},
+	}
+	expected := map[interface{}]interface{}{
+		"port":  "8080",
+		"count": 3,
+		"nested": map[interface{}]interface{}{
+			"list": []interface{}{"53", "plain", true},
+		},
+	}
+	res, err := testParameterValues().expandSpec(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %v, expected %v", res, expected)
+	}
+
+	spec = map[interface{}]interface{}{
+		"a": "${portmap.SSH}",
+		"b": []interface{}{"${portmap.FTP}"},
+	}
+	_, err = testParameterValues().expandSpec(spec)
+	if errs, ok := err.(Errors); !ok || len(errs) != 2 {
+		t.Errorf("expected two errors, got %v", err)
+	}
+}
+
+func TestFileValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipvsmesh-parameters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		f := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(f, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		expected map[string]string
+	}{
+		{"scalars", write("scalars.yaml", "HTTP: 8080\nhost: web\nenabled: true\n"), map[string]string{"HTTP": "8080", "host": "web", "enabled": "true"}},
+		{"empty", write("empty.yaml", ""), map[string]string{}},
+		{"nested", write("nested.yaml", "a:\n  b: 1\n"), nil},
+		{"list", write("list.yaml", "a: [1, 2]\n"), nil},
+		{"invalid", write("invalid.yaml", "a: [\n"), nil},
+		{"missing", filepath.Join(dir, "missing.yaml"), nil},
+		{"no file", "", nil},
+	}
+	for _, tt := range tests {
+		res, err := fileValues(tt.file)
+		if tt.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("%s: got %v, expected %v", tt.name, res, tt.expected)
+		}
+	}
+}
+
+func TestExpandParameters(t *testing.T) {
+	os.Setenv("IPVSMESH_TEST_ADDR", "10.1.0.1")
+	defer os.Unsetenv("IPVSMESH_TEST_ADDR")
+
+	cfg := &model.IPVSMeshConfig{
+		Parameters: map[string]model.Parameter{
+			"env": {Type: ParameterTypeEnv},
+			"sec": {Type: ParameterTypeEnv, Secret: true},
+		},
+		Services: []*model.Service{
+			{Name: "web", Address: "tcp://${env.IPVSMESH_TEST_ADDR}:80", Spec: map[interface{}]interface{}{"host": "${sec.IPVSMESH_TEST_ADDR}"}},
+		},
+	}
+	if err := ExpandMaskedParameters(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Services[0].Address != "tcp://10.1.0.1:80" {
+		t.Errorf("got address %q", cfg.Services[0].Address)
+	}
+	if cfg.Services[0].Spec["host"] != logging.RedactedValue {
+		t.Errorf("got host %q, expected secret to be masked", cfg.Services[0].Spec["host"])
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+	"github.com/aschmidt75/ipvsmesh/plugins"
+	log "github.com/sirupsen/logrus"
+)
+
+// InitializePlugins walks over services and publishers of cfg, parses
+// their spec fields according to plugin types and initializes the plugins.
+// On success, all services and publishers reference the globals of cfg.
+// All problems found, including invalid globals, are returned as Errors.
+func InitializePlugins(cfg *model.IPVSMeshConfig) error {
+	errs := validateGlobals(&cfg.Globals)
+
+	for _, service := range cfg.Services {
+		if err := initializeService(service, &cfg.Globals); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, publisher := range cfg.Publishers {
+		if fes := checkPublisher(publisher); len(fes) > 0 {
+			for _, fe := range fes {
+				errs = append(errs, fmt.Errorf("invalid %s of publisher %s: %s", fe.field, publisher.Name, fe.err))
+			}
+			continue
+		}
+		spec, err := plugins.ReadPublisherPluginSpecByTypeString(publisher)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to parse spec for publisher %s: %s", publisher.Name, err))
+			continue
+		}
+		log.WithFields(log.Fields{
+			"publisher": publisher.Name,
+			"name":      spec.Name(),
+		}).Trace("config: publisher spec")
+
+		if err := spec.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec for publisher %s: %s", publisher.Name, err))
+			continue
+		}
+
+		if err := spec.Initialize(&cfg.Globals); err != nil {
+			errs = append(errs, fmt.Errorf("unable to initialize plugin for publisher %s: %s", publisher.Name, err))
+			continue
+		}
+		publisher.Plugin = spec
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// inject refs to globals to all services and publishers
+	for _, service := range cfg.Services {
+		service.Globals = &cfg.Globals
+	}
+	for _, publisher := range cfg.Publishers {
+		publisher.Globals = &cfg.Globals
+	}
+
+	return nil
+}
+
+// ReleasePlugins closes the plugins of all services and publishers of
+// cfg which hold resources, see model.PluginCloser. It is used for
+// configurations which are initialized but never run.
+func ReleasePlugins(cfg *model.IPVSMeshConfig) {
+	for _, service := range cfg.Services {
+		releasePlugin(service.Plugin)
+	}
+	for _, publisher := range cfg.Publishers {
+		releasePlugin(publisher.Plugin)
+	}
+}
+
+func releasePlugin(plugin model.PluginSpec) {
+	if c, ok := plugin.(model.PluginCloser); ok {
+		if err := c.Close(); err != nil {
+			log.WithField("err", err).Warn("config: Unable to release plugin")
+		}
+	}
+}
+
+// InitializeService parses the spec of a single service according to its
+// plugin type and initializes the plugin with globals. On success, the
+// service references globals.
+func InitializeService(service *model.Service, globals *model.Globals) error {
+	if err := initializeService(service, globals); err != nil {
+		return err
+	}
+	service.Globals = globals
+	return nil
+}
+
+func initializeService(service *model.Service, globals *model.Globals) error {
+	if fes := checkService(service, globals); len(fes) > 0 {
+		var errs Errors
+		for _, fe := range fes {
+			errs = append(errs, fmt.Errorf("invalid %s of service %s: %s", fe.field, service.Name, fe.err))
+		}
+		return errs
+	}
+	spec, err := plugins.ReadPluginSpecByTypeString(service)
+	if err != nil {
+		return fmt.Errorf("unable to parse spec for service %s: %s", service.Name, err)
+	}
+	log.WithFields(log.Fields{
+		"service": service.Name,
+		"name":    spec.Name(),
+	}).Trace("config: service spec")
+
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("invalid spec for service %s: %s", service.Name, err)
+	}
+
+	if err := spec.Initialize(globals); err != nil {
+		return fmt.Errorf("unable to initialize plugin for service %s: %s", service.Name, err)
+	}
+	service.Plugin = spec
+	return nil
+}
+
+// validateGlobals checks settings of globals which are not
+// specific to a plugin.
+func validateGlobals(globals *model.Globals) Errors {
+	var errs Errors
+
+	switch globals.Shutdown.Policy {
+	case "", model.ShutdownKeep, model.ShutdownFlush, model.ShutdownDrain:
+	default:
+		errs = append(errs, fmt.Errorf("unknown shutdown policy: %s", globals.Shutdown.Policy))
+	}
+	if globals.Shutdown.DrainPeriod != "" {
+		if _, err := time.ParseDuration(globals.Shutdown.DrainPeriod); err != nil {
+			errs = append(errs, fmt.Errorf("invalid shutdown drain period: %s", err))
+		}
+	}
+
+	names := make([]string, 0, len(globals.Config))
+	for name := range globals.Config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := globals.Config[name]
+		if p.URL == "" {
+			errs = append(errs, fmt.Errorf("configuration profile %s: missing url", name))
+		}
+		if p.TLS != nil && (p.TLS.Cert == "") != (p.TLS.Key == "") {
+			errs = append(errs, fmt.Errorf("configuration profile %s: tls needs both cert and key", name))
+		}
+	}
+
+	for _, fe := range checkServiceOptions(globals.Defaults.ServiceOptions) {
+		errs = append(errs, fmt.Errorf("defaults: %s: %s", fe.field, fe.err))
+	}
+	types := make([]string, 0, len(globals.Defaults.Types))
+	for serviceType := range globals.Defaults.Types {
+		types = append(types, serviceType)
+	}
+	sort.Strings(types)
+	for _, serviceType := range types {
+		if _, err := plugins.NewServicePluginSpec(serviceType); err != nil {
+			errs = append(errs, fmt.Errorf("defaults: unknown service type %s", serviceType))
+			continue
+		}
+		for _, fe := range checkServiceOptions(globals.Defaults.Types[serviceType]) {
+			errs = append(errs, fmt.Errorf("defaults: types: %s: %s: %s", serviceType, fe.field, fe.err))
+		}
+	}
+
+	return errs
+}
+
+// fieldError is a problem with a single field of a service or publisher
+type fieldError struct {
+	field string
+	err   error
+}
+
+// checkService checks the plugin-independent fields of a service, and
+// its options with defaults of globals resolved
+func checkService(service *model.Service, globals *model.Globals) []fieldError {
+	var res []fieldError
+
+	if service.Name == "" {
+		res = append(res, fieldError{"name", errors.New("must not be empty")})
+	}
+	if err := model.ValidateAddress(service.Address); err != nil {
+		res = append(res, fieldError{"address", err})
+	}
+	res = append(res, checkServiceOptions(service.ServiceOptions)...)
+	if err := model.ValidateLabels(service.Labels); err != nil {
+		res = append(res, fieldError{"labels", err})
+	}
+	if len(res) > 0 {
+		return res
+	}
+
+	effective := service.ServiceOptions.WithDefaults(globals.ServiceDefaults(service.Type))
+	if err := model.ValidateSchedFlags(effective.SchedName, effective.SchedFlags); err != nil {
+		res = append(res, fieldError{"schedFlags", err})
+	}
+	if effective.Netmask != "" && effective.PersistenceTimeout == 0 {
+		res = append(res, fieldError{"netmask", errors.New("requires a persistenceTimeout")})
+	}
+	// defaults of onePacket only apply to udp services
+	if service.IsOnePacket() && !strings.HasPrefix(service.Address, "udp://") {
+		res = append(res, fieldError{"onePacket", errors.New("requires an udp:// address")})
+	}
+	if effective.UpperThreshold > 0 && effective.LowerThreshold > effective.UpperThreshold {
+		res = append(res, fieldError{"lowerThreshold", errors.New("must not exceed upperThreshold")})
+	}
+
+	return res
+}
+
+// checkServiceOptions checks options given for a service, or as defaults
+func checkServiceOptions(o model.ServiceOptions) []fieldError {
+	var res []fieldError
+
+	if o.SchedName != "" {
+		if err := model.ValidateScheduler(o.SchedName); err != nil {
+			res = append(res, fieldError{"sched", err})
+		}
+	}
+	if o.Forward != "" {
+		if err := model.ValidateForward(o.Forward); err != nil {
+			res = append(res, fieldError{"forward", err})
+		}
+	}
+	if o.Weight < 0 {
+		res = append(res, fieldError{"weight", errors.New("must not be negative")})
+	}
+	if o.PersistenceTimeout < 0 {
+		res = append(res, fieldError{"persistenceTimeout", errors.New("must not be negative")})
+	}
+	if o.Netmask != "" {
+		if err := model.ValidateNetmask(o.Netmask); err != nil {
+			res = append(res, fieldError{"netmask", err})
+		}
+	}
+	if err := model.ValidateSchedFlags(o.SchedName, o.SchedFlags); err != nil {
+		res = append(res, fieldError{"schedFlags", err})
+	}
+	if o.UpperThreshold < 0 {
+		res = append(res, fieldError{"upperThreshold", errors.New("must not be negative")})
+	}
+	if o.LowerThreshold < 0 {
+		res = append(res, fieldError{"lowerThreshold", errors.New("must not be negative")})
+	}
+
+	return res
+}
+
+// checkPublisher checks the plugin-independent fields of a publisher
+func checkPublisher(publisher *model.Publisher) []fieldError {
+	var res []fieldError
+
+	if publisher.Name == "" {
+		res = append(res, fieldError{"name", errors.New("must not be empty")})
+	}
+	if err := model.ValidateLabels(publisher.Labels); err != nil {
+		res = append(res, fieldError{"labels", err})
+	}
+	if err := model.ValidateLabels(publisher.MatchLabels); err != nil {
+		res = append(res, fieldError{"matchLabels", err})
+	}
+
+	return res
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// remoteFetchTimeout limits the time of fetching a remote configuration
+const remoteFetchTimeout = 10 * time.Second
+
+// RemoteSource is a configuration served over http(s). It is fetched
+// conditionally on the version fetched before. The last valid version
+// can be kept in a local cache file, to fall back to if the server
+// cannot be reached. There is no cache by default.
+type RemoteSource struct {
+	URL       string
+	CacheFile string
+
+	client       *http.Client
+	etag         string
+	lastModified string
+}
+
+// IsRemote returns true if path is a http(s) URL
+func IsRemote(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// NewRemoteSource creates a source for url. If caFile is given, the server
+// certificate is verified against it instead of the system's CAs. If
+// cacheFile is empty, there is no fallback.
+func NewRemoteSource(url, caFile, cacheFile string) (*RemoteSource, error) {
+	if !IsRemote(url) {
+		return nil, fmt.Errorf("not a http(s) URL: %s", url)
+	}
+
+	tlsConfig := &tls.Config{}
+	if caFile != "" {
+		b, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New("no CA certificates found in " + caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &RemoteSource{
+		URL:       url,
+		CacheFile: cacheFile,
+		client: &http.Client{
+			Timeout: remoteFetchTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}, nil
+}
+
+// Fetch requests the configuration from the server. Unless force is set,
+// the request is conditional on the version fetched before, and nil is
+// returned if it has not been modified. It also returns the modification
+// time as given by the server, or the current time.
+func (r *RemoteSource) Fetch(force bool) ([]byte, time.Time, error) {
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if !force {
+		if r.etag != "" {
+			req.Header.Set("If-None-Match", r.etag)
+		}
+		if r.lastModified != "" {
+			req.Header.Set("If-Modified-Since", r.lastModified)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, time.Time{}, nil
+	default:
+		return nil, time.Time{}, fmt.Errorf("unexpected response %s from %s", resp.Status, r.URL)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	r.etag = resp.Header.Get("ETag")
+	r.lastModified = resp.Header.Get("Last-Modified")
+
+	mt, err := http.ParseTime(r.lastModified)
+	if err != nil {
+		mt = time.Now()
+	}
+	return b, mt, nil
+}
+
+// ReadCache returns the cached configuration and its modification time.
+// As the configuration controls what the daemon executes, the cache file
+// must be a regular file, owned by root or the daemon's user and not
+// writable by group or others. Symbolic links are not followed.
+func (r *RemoteSource) ReadCache() ([]byte, time.Time, error) {
+	if r.CacheFile == "" {
+		return nil, time.Time{}, errors.New("no cache file configured")
+	}
+	f, err := os.OpenFile(r.CacheFile, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := checkCacheFile(info); err != nil {
+		return nil, time.Time{}, fmt.Errorf("refusing cache file %s: %s", r.CacheFile, err)
+	}
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return b, info.ModTime(), nil
+}
+
+// checkCacheFile checks type, owner and permissions of the cache file
+func checkCacheFile(info os.FileInfo) error {
+	if !info.Mode().IsRegular() {
+		return errors.New("not a regular file")
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return errors.New("writable by group or others")
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.New("unable to determine owner")
+	}
+	if st.Uid != 0 && int(st.Uid) != os.Geteuid() {
+		return fmt.Errorf("owned by uid %d", st.Uid)
+	}
+	return nil
+}
+
+// WriteCache replaces the cached configuration by b. It does
+// nothing if no cache file is configured.
+func (r *RemoteSource) WriteCache(b []byte) error {
+	if r.CacheFile == "" {
+		return nil
+	}
+	return writeFileAtomic(r.CacheFile, b, 0600)
+}
+
+// ReadModel parses a configuration fetched from the source, like
+// ReadModelFromInput does for files. Services and publishers
+// reference the URL as their source.
+func (r *RemoteSource) ReadModel(b []byte) (*model.IPVSMeshConfig, error) {
+	cfg, err := ReadInterpolatedModelFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	setSource(cfg, r.URL)
+	return cfg, nil
+}
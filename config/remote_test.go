@@ -0,0 +1,217 @@
+package config
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const remoteTestConfig = "apiVersion: ipvsmesh/v2\nservices: []\n"
+
+var remoteTestModTime = time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// conditionalHandler serves remoteTestConfig with ETag and Last-Modified,
+// and records the conditional headers of all requests.
+type conditionalHandler struct {
+	status  int
+	headers []http.Header
+}
+
+func (h *conditionalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.headers = append(h.headers, r.Header.Clone())
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+		return
+	}
+
+	w.Header().Set("ETag", `"v1"`)
+	w.Header().Set("Last-Modified", remoteTestModTime.Format(http.TimeFormat))
+	if r.Header.Get("If-None-Match") == `"v1"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write([]byte(remoteTestConfig))
+}
+
+func TestRemoteSourceConditionalFetch(t *testing.T) {
+	h := &conditionalHandler{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	r, err := NewRemoteSource(srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, mt, err := r.Fetch(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != remoteTestConfig {
+		t.Errorf("got %q, expected %q", b, remoteTestConfig)
+	}
+	if !mt.Equal(remoteTestModTime) {
+		t.Errorf("got modification time %s, expected %s", mt, remoteTestModTime)
+	}
+
+	b, _, err = r.Fetch(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != nil {
+		t.Errorf("expected no content for an unmodified config, got %q", b)
+	}
+	second := h.headers[1]
+	if v := second.Get("If-None-Match"); v != `"v1"` {
+		t.Errorf("got If-None-Match %q, expected %q", v, `"v1"`)
+	}
+	if v := second.Get("If-Modified-Since"); v != remoteTestModTime.Format(http.TimeFormat) {
+		t.Errorf("got If-Modified-Since %q", v)
+	}
+
+	// forced fetches are not conditional
+	b, _, err = r.Fetch(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != remoteTestConfig {
+		t.Errorf("forced fetch: got %q, expected %q", b, remoteTestConfig)
+	}
+	if v := h.headers[2].Get("If-None-Match"); v != "" {
+		t.Errorf("forced fetch: got If-None-Match %q", v)
+	}
+}
+
+func TestRemoteSourceFallbackToCache(t *testing.T) {
+	h := &conditionalHandler{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "ipvsmesh-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cacheFile := filepath.Join(dir, "cache.yaml")
+
+	r, err := NewRemoteSource(srv.URL, "", cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := r.Fetch(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteCache(b); err != nil {
+		t.Fatal(err)
+	}
+
+	h.status = http.StatusInternalServerError
+	if _, _, err := r.Fetch(true); err == nil {
+		t.Fatal("expected an error for status 500")
+	}
+
+	cached, _, err := r.ReadCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cached) != remoteTestConfig {
+		t.Errorf("got cached %q, expected %q", cached, remoteTestConfig)
+	}
+	if _, err := r.ReadModel(cached); err != nil {
+		t.Errorf("unable to read cached config: %s", err)
+	}
+}
+
+func TestRemoteSourceRefusesUnsafeCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipvsmesh-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writable := filepath.Join(dir, "writable.yaml")
+	if err := ioutil.WriteFile(writable, []byte(remoteTestConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(writable, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(dir, "target.yaml")
+	if err := ioutil.WriteFile(target, []byte(remoteTestConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cacheFile string
+		valid     bool
+	}{
+		{"none", "", false},
+		{"missing", filepath.Join(dir, "missing.yaml"), false},
+		{"writable by others", writable, false},
+		{"symlink", link, false},
+		{"private", target, true},
+	}
+	for _, tt := range tests {
+		r := &RemoteSource{CacheFile: tt.cacheFile}
+		_, _, err := r.ReadCache()
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %s", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestRemoteSourceCA(t *testing.T) {
+	srv := httptest.NewTLSServer(&conditionalHandler{})
+	defer srv.Close()
+
+	// the test server's certificate is not trusted by the system
+	r, err := NewRemoteSource(srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Fetch(false); err == nil {
+		t.Error("expected a certificate error without CA")
+	}
+
+	dir, err := ioutil.TempDir("", "ipvsmesh-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = NewRemoteSource(srv.URL, caFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := r.Fetch(false)
+	if err != nil {
+		t.Fatalf("unable to fetch with CA: %s", err)
+	}
+	if string(b) != remoteTestConfig {
+		t.Errorf("got %q, expected %q", b, remoteTestConfig)
+	}
+
+	if _, err := NewRemoteSource(srv.URL, filepath.Join(dir, "missing.pem"), ""); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+	"gopkg.in/yaml.v2"
+)
+
+// stateFile is the layout of the file persisting services
+// registered at runtime.
+type stateFile struct {
+	Services []*model.Service `yaml:"services"`
+}
+
+// ReadStateFile reads services registered at runtime from a state file.
+// A missing file is not an error, it yields no services.
+func ReadStateFile(filename string) ([]*model.Service, error) {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return []*model.Service{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	st := stateFile{}
+	if err := yaml.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	for _, service := range st.Services {
+		service.Runtime = true
+	}
+	return st.Services, nil
+}
+
+// WriteStateFile persists services registered at runtime. The file is
+// replaced atomically so that a crash does not leave a partial file.
+func WriteStateFile(filename string, services []*model.Service) error {
+	b, err := yaml.Marshal(stateFile{Services: services})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, b, 0600)
+}
+
+// writeFileAtomic writes b to a temporary file next to filename
+// and renames it to filename.
+func writeFileAtomic(filename string, b []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
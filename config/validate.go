@@ -0,0 +1,385 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+	"github.com/aschmidt75/ipvsmesh/plugins"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a configuration file, at the
+// position of the offending yaml node if known.
+type ValidationError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line > 0 && e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// yamlErrorLineRegexp extracts the line number of yaml syntax errors
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// validatedFile is a configuration file read by the validator
+type validatedFile struct {
+	name string
+	doc  *yamlv3.Node
+	cfg  *model.IPVSMeshConfig
+}
+
+// validator collects all problems of a configuration
+type validator struct {
+	errs Errors
+
+	// locations of service and publisher names, to report duplicates
+	serviceNames   map[string]string
+	publisherNames map[string]string
+}
+
+// Validate checks the configuration at path, which is a file or a
+// directory of files, without initializing plugins. Unknown fields,
+// values of wrong types, addresses, schedulers, forward methods, label
+// selectors and plugin specs are checked. All problems found are
+// returned as Errors of *ValidationError.
+func Validate(path string) error {
+	files, err := ConfigFiles(path)
+	if err != nil {
+		return err
+	}
+
+	v := &validator{
+		serviceNames:   make(map[string]string),
+		publisherNames: make(map[string]string),
+	}
+
+	// parameters and defaults may be defined in any file of a directory
+	read := make([]*validatedFile, 0, len(files))
+	merged := &model.IPVSMeshConfig{}
+	for _, file := range files {
+		f := v.readFile(file)
+		if f == nil {
+			continue
+		}
+		read = append(read, f)
+		mergeModel(merged, &model.IPVSMeshConfig{Parameters: f.cfg.Parameters, Globals: f.cfg.Globals})
+	}
+
+	values, err := ResolveParameters(merged.Parameters)
+	if err != nil {
+		v.errs = append(v.errs, &ValidationError{File: path, Msg: err.Error()})
+	}
+
+	for _, f := range read {
+		v.validateFile(f, &merged.Globals, values)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+func (v *validator) add(file string, node *yamlv3.Node, format string, args ...interface{}) {
+	e := &ValidationError{File: file, Msg: fmt.Sprintf(format, args...)}
+	if node != nil {
+		e.Line, e.Column = node.Line, node.Column
+	}
+	v.errs = append(v.errs, e)
+}
+
+// readFile parses a file and checks its structure. It returns nil
+// if the file cannot be decoded.
+func (v *validator) readFile(file string) *validatedFile {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		v.errs = append(v.errs, &ValidationError{File: file, Msg: err.Error()})
+		return nil
+	}
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(b, &root); err != nil {
+		e := &ValidationError{File: file, Msg: err.Error()}
+		if m := yamlErrorLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+		}
+		v.errs = append(v.errs, e)
+		return nil
+	}
+	if len(root.Content) == 0 {
+		// empty file
+		return &validatedFile{name: file, cfg: &model.IPVSMeshConfig{}}
+	}
+	doc := root.Content[0]
+
+	// check older layouts as they will be read
+	if doc.Kind == yamlv3.MappingNode {
+		if _, err := migrateDocument(doc); err != nil {
+			v.add(file, mappingValue(doc, "apiVersion"), "%s", err)
+			return nil
+		}
+	}
+
+	numErrs := len(v.errs)
+	v.checkNode(file, doc, reflect.TypeOf(model.IPVSMeshConfig{}), "")
+	if len(v.errs) > numErrs {
+		return nil
+	}
+
+	cfg, err := ReadModelFromBytes(b)
+	if err != nil {
+		v.errs = append(v.errs, &ValidationError{File: file, Msg: err.Error()})
+		return nil
+	}
+	if err := Interpolate(cfg); err != nil {
+		v.errs = append(v.errs, &ValidationError{File: file, Msg: err.Error()})
+		return nil
+	}
+	return &validatedFile{name: file, doc: doc, cfg: cfg}
+}
+
+// validateFile checks globals, services and publishers of a file.
+// Services are checked against the merged globals of all files.
+func (v *validator) validateFile(f *validatedFile, globals *model.Globals, values ParameterValues) {
+	if f.doc == nil {
+		return
+	}
+
+	globalsNode := mappingValue(f.doc, "globals")
+	for _, err := range validateGlobals(&f.cfg.Globals) {
+		v.add(f.name, globalsNode, "globals: %s", err)
+	}
+
+	servicesNode := mappingValue(f.doc, "services")
+	for idx, service := range f.cfg.Services {
+		node := servicesNode.Content[idx]
+		v.validateService(f.name, node, service, globals, values)
+	}
+
+	publishersNode := mappingValue(f.doc, "publishers")
+	for idx, publisher := range f.cfg.Publishers {
+		node := publishersNode.Content[idx]
+		v.validatePublisher(f.name, node, publisher, values)
+	}
+}
+
+func (v *validator) validateService(file string, node *yamlv3.Node, service *model.Service, globals *model.Globals, values ParameterValues) {
+	prefix := fmt.Sprintf("service %s", service.Name)
+	location := fmt.Sprintf("%s:%d", file, node.Line)
+	if other, ex := v.serviceNames[service.Name]; ex && service.Name != "" {
+		v.add(file, mappingValue(node, "name"), "%s: duplicate name, already defined at %s", prefix, other)
+	}
+	v.serviceNames[service.Name] = location
+
+	if err := values.expandService(service); err != nil {
+		v.add(file, node, "%s: %s", prefix, err)
+		return
+	}
+
+	for _, fe := range checkService(service, globals) {
+		v.add(file, fieldNode(node, fe.field), "%s: %s: %s", prefix, fe.field, fe.err)
+	}
+
+	empty, err := plugins.NewServicePluginSpec(service.Type)
+	if err != nil {
+		v.add(file, fieldNode(node, "type"), "%s: unknown type %s", prefix, service.Type)
+		return
+	}
+	if !v.checkSpecNode(file, mappingValue(node, "spec"), prefix, empty) {
+		return
+	}
+	spec, err := plugins.ReadPluginSpecByTypeString(service)
+	v.validateSpec(file, fieldNode(node, "spec"), prefix, spec, err)
+}
+
+func (v *validator) validatePublisher(file string, node *yamlv3.Node, publisher *model.Publisher, values ParameterValues) {
+	prefix := fmt.Sprintf("publisher %s", publisher.Name)
+	location := fmt.Sprintf("%s:%d", file, node.Line)
+	if other, ex := v.publisherNames[publisher.Name]; ex && publisher.Name != "" {
+		v.add(file, mappingValue(node, "name"), "%s: duplicate name, already defined at %s", prefix, other)
+	}
+	v.publisherNames[publisher.Name] = location
+
+	if err := values.expandPublisher(publisher); err != nil {
+		v.add(file, node, "%s: %s", prefix, err)
+		return
+	}
+
+	for _, fe := range checkPublisher(publisher) {
+		v.add(file, fieldNode(node, fe.field), "%s: %s: %s", prefix, fe.field, fe.err)
+	}
+
+	empty, err := plugins.NewPublisherPluginSpec(publisher.Type)
+	if err != nil {
+		v.add(file, fieldNode(node, "type"), "%s: unknown type %s", prefix, publisher.Type)
+		return
+	}
+	if !v.checkSpecNode(file, mappingValue(node, "spec"), prefix, empty) {
+		return
+	}
+	spec, err := plugins.ReadPublisherPluginSpecByTypeString(publisher)
+	v.validateSpec(file, fieldNode(node, "spec"), prefix, spec, err)
+}
+
+// checkSpecNode checks the structure of a plugin spec against the spec
+// type of the plugin. It returns false if problems have been found.
+func (v *validator) checkSpecNode(file string, node *yamlv3.Node, prefix string, empty model.PluginSpec) bool {
+	numErrs := len(v.errs)
+	v.checkNode(file, node, reflect.TypeOf(empty), prefix+": spec")
+	return len(v.errs) == numErrs
+}
+
+// validateSpec reports errors of decoding a plugin spec, and lets
+// the plugin validate it.
+func (v *validator) validateSpec(file string, node *yamlv3.Node, prefix string, spec model.PluginSpec, err error) {
+	if err != nil {
+		v.add(file, node, "%s: spec: %s", prefix, err)
+		return
+	}
+	if err := spec.Validate(); err != nil {
+		v.add(file, node, "%s: spec: %s", prefix, err)
+	}
+}
+
+// checkNode reports unknown fields and values that cannot be decoded
+// into a value of type t.
+func (v *validator) checkNode(file string, node *yamlv3.Node, t reflect.Type, path string) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			v.add(file, node, "%s: expected a mapping", displayPath(path))
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, val := node.Content[i], node.Content[i+1]
+			ft, ex := fields[k.Value]
+			if !ex {
+				v.add(file, k, "%s: unknown field %s", displayPath(path), k.Value)
+				continue
+			}
+			v.checkNode(file, val, ft, joinPath(path, k.Value))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yamlv3.SequenceNode {
+			v.add(file, node, "%s: expected a list", displayPath(path))
+			return
+		}
+		for idx, item := range node.Content {
+			v.checkNode(file, item, t.Elem(), fmt.Sprintf("%s[%d]", path, idx))
+		}
+
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			v.add(file, node, "%s: expected a mapping", displayPath(path))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.checkNode(file, node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+
+	default:
+		if node.Kind != yamlv3.ScalarNode {
+			v.add(file, node, "%s: expected a single value", displayPath(path))
+			return
+		}
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			v.add(file, node, "%s: invalid value %q, expected %s", displayPath(path), node.Value, t.Kind())
+		}
+	}
+}
+
+// yamlFields returns the types of all fields of struct type t by yaml name
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	res := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if len(parts) > 1 && parts[1] == "inline" {
+			for k, ft := range yamlFields(f.Type) {
+				res[k] = ft
+			}
+			continue
+		}
+		name := parts[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		res[name] = f.Type
+	}
+	return res
+}
+
+// mappingValue returns the value node of key within a mapping node
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// fieldNode returns the value node of key, or node itself if key is not set
+func fieldNode(node *yamlv3.Node, key string) *yamlv3.Node {
+	if n := mappingValue(node, key); n != nil {
+		return n
+	}
+	return node
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeConfigFiles writes files by name into a new temporary directory
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "ipvsmesh-validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// validationErrors returns the errors of Validate relative to dir
+func validationErrors(t *testing.T, dir, path string) []string {
+	err := Validate(path)
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got %T: %s", err, err)
+	}
+	res := make([]string, len(errs))
+	for idx, e := range errs {
+		ve, ok := e.(*ValidationError)
+		if !ok {
+			t.Fatalf("expected *ValidationError, got %T: %s", e, e)
+		}
+		rel, _ := filepath.Rel(dir, ve.File)
+		ve.File = rel
+		res[idx] = ve.Error()
+	}
+	return res
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "valid",
+			content: `apiVersion: ipvsmesh/v2
+services:
+  - name: web
+    type: proxyFromFile
+    address: tcp://10.0.0.1:80
+    spec:
+      file: backends.txt
+      type: text
+`,
+		},
+		{
+			name: "unknown fields",
+			content: `globals:
+  ipvsctl:
+    executionType: file-only
+    flie: /tmp/x
+services:
+  - name: web
+    type: proxyFromFile
+    address: tcp://10.0.0.1:80
+    wieght: 10
+    spec:
+      file: backends.txt
+      typo: text
+`,
+			expected: []string{
+				"test.yaml:4:5: globals.ipvsctl: unknown field flie",
+				"test.yaml:9:5: services[0]: unknown field wieght",
+			},
+		},
+		{
+			name: "invalid values",
+			content: `services:
+  - name: web
+    type: proxyFromFile
+    address: tcp://10.0.0.1:80
+    weight: heavy
+`,
+			expected: []string{
+				`test.yaml:5:13: services[0].weight: invalid value "heavy", expected int`,
+			},
+		},
+		{
+			name: "invalid address and spec",
+			content: `services:
+  - name: web
+    type: proxyFromFile
+    address: 10.0.0.1
+    spec:
+      file: backends.txt
+      typo: text
+`,
+			expected: []string{
+				"test.yaml:4:14: service web: address: invalid address 10.0.0.1: address 10.0.0.1: missing port in address",
+				"test.yaml:7:7: service web: spec: unknown field typo",
+			},
+		},
+		{
+			name:    "syntax error",
+			content: "services:\n  - name: web\n   type: x\n",
+			expected: []string{
+				"test.yaml:2: yaml: line 2: did not find expected '-' indicator",
+			},
+		},
+	}
+	for _, tt := range tests {
+		dir := writeConfigFiles(t, map[string]string{"test.yaml": tt.content})
+		res := validationErrors(t, dir, filepath.Join(dir, "test.yaml"))
+		os.RemoveAll(dir)
+		if !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("%s: got %q, expected %q", tt.name, res, tt.expected)
+		}
+	}
+}
+
+func TestValidateDirectory(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"10-globals.yaml": `globals:
+  defaults:
+    persistenceTimeout: 300
+`,
+		"20-web.yaml": `services:
+  - name: web
+    type: proxyFromFile
+    address: tcp://10.0.0.1:80
+    netmask: 255.255.255.0
+    spec:
+      file: backends.txt
+      type: text
+`,
+		"30-more.yaml": `services:
+  - name: other
+    type: proxyFromFile
+    address: tcp://10.0.0.2:80
+    spec:
+      file: backends.txt
+      type: text
+  - name: web
+    type: proxyFromFile
+    address: tcp://10.0.0.3:80
+    spec:
+      file: backends.txt
+      type: text
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	// the netmask of web requires the persistenceTimeout of another file
+	res := validationErrors(t, dir, dir)
+	expected := []string{
+		"30-more.yaml:8:11: service web: duplicate name, already defined at " + filepath.Join(dir, "20-web.yaml") + ":2",
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %q, expected %q", res, expected)
+	}
+}
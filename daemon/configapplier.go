@@ -1,12 +1,18 @@
 package daemon
 
 import (
+	"context"
+	"errors"
 	"sync"
 
+	"github.com/aschmidt75/ipvsmesh/config"
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	log "github.com/sirupsen/logrus"
 )
 
+var logConfigApplier = logging.Component("configapplier")
+
 // ConfigUpdateChanType is a channel that transmits updated configuration
 type ConfigUpdateChanType chan model.IPVSMeshConfig
 
@@ -19,53 +25,161 @@ type ConfigApplierWorker struct {
 	ipvsUpdateChan            IPVSApplierChanType
 	publisherConfigUpdateChan PublisherConfigUpdateChanType
 	wg                        sync.WaitGroup
-	scWorkers                 chan *sync.WaitGroup
+
+	// last configuration read from file, nil until the first one arrives
+	cfg *model.IPVSMeshConfig
+
+	// services registered at runtime, merged into every configuration
+	// and persisted to stateFile if given
+	mu              sync.Mutex
+	runtimeServices []*model.Service
+	runtimeChan     chan runtimeServiceRequest
+	stateFile       string
+}
+
+// runtimeServiceRequest adds a service (if add is set) or removes
+// the service named remove. The result is sent to resCh.
+type runtimeServiceRequest struct {
+	add    *model.Service
+	remove string
+	resCh  chan error
 }
 
+// Errors of runtime service registration
+var (
+	errNotConfigured   = errors.New("no configuration has been applied yet")
+	errServiceExists   = errors.New("service already exists")
+	errNoSuchService   = errors.New("no such service")
+	errNotRuntimeOwned = errors.New("service is defined in the configuration file")
+)
+
 // NewConfigApplierWorker creates a Configuration applier worker based on
-// an update channel.
-func NewConfigApplierWorker(updateChan ConfigUpdateChanType, ipvsUpdateChan IPVSApplierChanType, publisherConfigUpdateChan PublisherConfigUpdateChanType) *ConfigApplierWorker {
+// an update channel. Services registered at runtime are persisted to
+// stateFile, unless it is empty.
+func NewConfigApplierWorker(updateChan ConfigUpdateChanType, ipvsUpdateChan IPVSApplierChanType, publisherConfigUpdateChan PublisherConfigUpdateChanType, stateFile string) *ConfigApplierWorker {
 	sc := make(chan *sync.WaitGroup, 1)
-	scWorkers := make(chan *sync.WaitGroup, 1)
 
 	return &ConfigApplierWorker{
 		StoppableByChan: StoppableByChan{
 			StopChan: &sc,
+			Name:     "configapplier",
 		},
 		updateChan:                updateChan,
-		scWorkers:                 scWorkers,
 		ipvsUpdateChan:            ipvsUpdateChan,
 		publisherConfigUpdateChan: publisherConfigUpdateChan,
+		runtimeServices:           make([]*model.Service, 0),
+		runtimeChan:               make(chan runtimeServiceRequest),
+		stateFile:                 stateFile,
 	}
 }
 
+// AddService registers a service at runtime. It is initialized against
+// the globals of the current configuration and applied immediately.
+func (s *ConfigApplierWorker) AddService(ctx context.Context, service *model.Service) error {
+	return s.runtimeRequest(ctx, runtimeServiceRequest{add: service})
+}
+
+// RemoveService removes a service that has been registered at runtime
+func (s *ConfigApplierWorker) RemoveService(ctx context.Context, name string) error {
+	return s.runtimeRequest(ctx, runtimeServiceRequest{remove: name})
+}
+
+func (s *ConfigApplierWorker) runtimeRequest(ctx context.Context, req runtimeServiceRequest) error {
+	req.resCh = make(chan error, 1)
+	select {
+	case s.runtimeChan <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.resCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RuntimeServices returns all services registered at runtime
+func (s *ConfigApplierWorker) RuntimeServices() []*model.Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]*model.Service, len(s.runtimeServices))
+	copy(res, s.runtimeServices)
+	return res
+}
+
+// WithRuntimeServices returns a copy of cfg with all runtime services
+// added that do not conflict with services of cfg.
+func (s *ConfigApplierWorker) WithRuntimeServices(cfg model.IPVSMeshConfig) model.IPVSMeshConfig {
+	names := make(map[string]bool, len(cfg.Services))
+	for _, service := range cfg.Services {
+		names[service.Name] = true
+	}
+
+	services := make([]*model.Service, len(cfg.Services))
+	copy(services, cfg.Services)
+	for _, service := range s.RuntimeServices() {
+		if names[service.Name] {
+			logConfigApplier.WithField("name", service.Name).Warn("configapplier: Runtime service is shadowed by a service of the same name in the configuration file")
+			continue
+		}
+		if service.Plugin == nil {
+			continue
+		}
+		services = append(services, service)
+	}
+	cfg.Services = services
+	return cfg
+}
+
 func (s *ConfigApplierWorker) Worker() {
-	log.Info("configapplier: Starting Configuration applier...")
+	logConfigApplier.Info("configapplier: Starting Configuration applier...")
+	defer s.markExited()
+
+	if s.stateFile != "" {
+		services, err := config.ReadStateFile(s.stateFile)
+		if err != nil {
+			logConfigApplier.WithFields(log.Fields{
+				"err":  err,
+				"file": s.stateFile,
+			}).Error("configapplier: Unable to read state file, starting without runtime services")
+		} else {
+			logConfigApplier.WithField("num", len(services)).Info("configapplier: Restored runtime services from state file")
+			s.mu.Lock()
+			s.runtimeServices = services
+			s.mu.Unlock()
+		}
+	}
+
 	for {
 		select {
 		case cfg := <-s.updateChan:
-			log.WithField("cfg", cfg).Debug("configapplier: Received new config")
-
-			// apply config to publisher worker
-			s.publisherConfigUpdateChan <- cfg
+			logConfigApplier.WithFields(log.Fields{
+				"numServices":   len(cfg.Services),
+				"numPublishers": len(cfg.Publishers),
+			}).Debug("configapplier: Received new config")
+			s.cfg = &cfg
+			s.initializeRuntimeServices(&cfg.Globals)
 
-			err := s.applyServices(cfg)
+			err := s.applyConfig(s.WithRuntimeServices(cfg))
 			if err != nil {
-				log.WithField("err", err).Error("configapplier: Unable to apply configuration (services)")
+				logConfigApplier.WithField("err", err).Error("configapplier: Unable to apply configuration (services)")
 			}
 
 			// done.
-			log.WithField("numServicesActive", GetAllServiceWorkers().Len()).Info("configapplier: Applied new configuration")
+			logConfigApplier.WithField("numServicesActive", GetAllServiceWorkers().Len()).Info("configapplier: Applied new configuration")
+
+		case req := <-s.runtimeChan:
+			req.resCh <- s.handleRuntimeRequest(req)
 
 		case wg := <-*s.StoppableByChan.StopChan:
-			log.Info("configapplier: Stopping")
+			logConfigApplier.Info("configapplier: Stopping")
 
 			// stop all active service workers
-			l := GetAllServiceWorkers()
-			for e := l.Front(); e != nil; e = e.Next() {
-				sw := e.Value.(*ServiceWorker)
+			all := removeServiceWorkers(func(sw *ServiceWorker) bool { return true })
+			for _, sw := range all {
 				*sw.StopChan <- &s.wg
-				l.Remove(e)
 			}
 
 			wg.Done()
@@ -74,8 +188,16 @@ func (s *ConfigApplierWorker) Worker() {
 	}
 }
 
+// applyConfig passes cfg on to the publisher worker and applies its services
+func (s *ConfigApplierWorker) applyConfig(cfg model.IPVSMeshConfig) error {
+	// apply config to publisher worker
+	s.publisherConfigUpdateChan <- cfg
+
+	return s.applyServices(cfg)
+}
+
 func (s *ConfigApplierWorker) applyServices(cfg model.IPVSMeshConfig) error {
-	log.Debug("configapplier: Applying services...")
+	logConfigApplier.Debug("configapplier: Applying services...")
 
 	// force ipvsapplier to clear caches
 	s.ipvsUpdateChan <- IPVSApplierUpdateStruct{
@@ -88,15 +210,13 @@ func (s *ConfigApplierWorker) applyServices(cfg model.IPVSMeshConfig) error {
 	}
 
 	// walk active service worker, check if they're still part of the model.
-	l := GetAllServiceWorkers()
-	for e := l.Front(); e != nil; e = e.Next() {
-		sw := e.Value.(*ServiceWorker)
+	removed := removeServiceWorkers(func(sw *ServiceWorker) bool {
 		_, ex := m[sw.service.Name]
-		if !ex {
-			log.WithField("name", sw.service.Name).Debug("configapplier: Taking down because not part of model any more")
-			*sw.StopChan <- &s.wg
-			l.Remove(e)
-		}
+		return !ex
+	})
+	for _, sw := range removed {
+		logConfigApplier.WithField("name", sw.service.Name).Debug("configapplier: Taking down because not part of model any more")
+		*sw.StopChan <- &s.wg
 	}
 
 	// walk new config, add/update workers
@@ -110,18 +230,110 @@ func (s *ConfigApplierWorker) applyServices(cfg model.IPVSMeshConfig) error {
 }
 
 func (s *ConfigApplierWorker) applyService(cfg model.IPVSMeshConfig, service *model.Service) error {
-	log.WithField("name", service.Name).Debug("configapplier: Applying service...")
+	logConfigApplier.WithField("name", service.Name).Debug("configapplier: Applying service...")
 
 	sw := GetServiceWorkerByName(service.Name)
 	if sw == nil {
 		// create
-		sw = NewServiceWorker(s.scWorkers, &cfg, service, s.ipvsUpdateChan)
+		sw = NewServiceWorker(&cfg, service, s.ipvsUpdateChan)
 		s.wg.Add(1)
 		go sw.Worker()
 	} else {
 		sw.Update(service)
 	}
-	log.WithField("sw", sw).Debug("configapplier: Activated/Updated service worker")
+	logConfigApplier.WithField("sw", sw).Debug("configapplier: Activated/Updated service worker")
 
 	return nil
 }
+
+// initializeRuntimeServices (re-)initializes the plugins of all runtime
+// services against globals of a new configuration. Services whose plugin
+// cannot be initialized are kept, but not applied.
+func (s *ConfigApplierWorker) initializeRuntimeServices(globals *model.Globals) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, service := range s.runtimeServices {
+		// work on a copy, active service workers still use the old one
+		svc := *service
+		if err := config.InitializeService(&svc, globals); err != nil {
+			logConfigApplier.WithFields(log.Fields{
+				"err":  err,
+				"name": service.Name,
+			}).Error("configapplier: Unable to initialize runtime service, not applying it")
+			emitEvent(EventConfigRejected, service.Name, err.Error(), map[string]string{"runtime": "true"})
+			svc.Plugin = nil
+		}
+		s.runtimeServices[idx] = &svc
+	}
+}
+
+// handleRuntimeRequest adds or removes a runtime service, persists
+// all runtime services and re-applies the configuration.
+func (s *ConfigApplierWorker) handleRuntimeRequest(req runtimeServiceRequest) error {
+	if s.cfg == nil {
+		return errNotConfigured
+	}
+
+	isFileService := func(name string) bool {
+		for _, service := range s.cfg.Services {
+			if service.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	s.mu.Lock()
+	if req.add != nil {
+		service := req.add
+		if isFileService(service.Name) {
+			s.mu.Unlock()
+			return errServiceExists
+		}
+		for _, rs := range s.runtimeServices {
+			if rs.Name == service.Name {
+				s.mu.Unlock()
+				return errServiceExists
+			}
+		}
+
+		service.Runtime = true
+		if err := config.InitializeService(service, &s.cfg.Globals); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		s.runtimeServices = append(s.runtimeServices, service)
+		logConfigApplier.WithField("name", service.Name).Info("configapplier: Added runtime service")
+	} else {
+		idx := -1
+		for i, rs := range s.runtimeServices {
+			if rs.Name == req.remove {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			s.mu.Unlock()
+			if isFileService(req.remove) {
+				return errNotRuntimeOwned
+			}
+			return errNoSuchService
+		}
+		s.runtimeServices = append(s.runtimeServices[:idx], s.runtimeServices[idx+1:]...)
+		logConfigApplier.WithField("name", req.remove).Info("configapplier: Removed runtime service")
+	}
+	services := make([]*model.Service, len(s.runtimeServices))
+	copy(services, s.runtimeServices)
+	s.mu.Unlock()
+
+	if s.stateFile != "" {
+		if err := config.WriteStateFile(s.stateFile, services); err != nil {
+			logConfigApplier.WithFields(log.Fields{
+				"err":  err,
+				"file": s.stateFile,
+			}).Error("configapplier: Unable to write state file")
+		}
+	}
+
+	return s.applyConfig(s.WithRuntimeServices(*s.cfg))
+}
@@ -1,53 +1,121 @@
 package daemon
 
 import (
-	"os"
+	"context"
+	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/aschmidt75/ipvsmesh/config"
-	"github.com/aschmidt75/ipvsmesh/plugins"
+	"github.com/aschmidt75/ipvsmesh/logging"
+	"github.com/aschmidt75/ipvsmesh/model"
 	"github.com/radovskyb/watcher"
 	log "github.com/sirupsen/logrus"
 )
 
+var logConfigWatcher = logging.Component("configwatcher")
+
+// localNetworkCheckInterval is the interval of checking local interface
+// addresses for changes, if they are referenced as parameters
+const localNetworkCheckInterval = 5 * time.Second
+
 // ConfigWatcherWorker is a continuously running loop
-// watching changes on a given config file. If the file
-// changes, it is read, parsed and passed on to an
-// update channel.
+// watching changes on a given config file, or on all
+// *.yaml files of a config directory, or polling a remote
+// configuration. If it changes, the configuration is read,
+// parsed and passed on to an update channel.
 type ConfigWatcherWorker struct {
 	StoppableByChan
 
 	configFileName string
 	lastModTime    time.Time
 	updateChan     ConfigUpdateChanType
+	reloadChan     chan chan error
+	mu             sync.Mutex
+	loaded         bool // a valid configuration has been read
+	numServices    int  // number of services of the loaded configuration
+
+	// local interface addresses the current configuration has been
+	// expanded with, nil if it does not reference them.
+	localAddresses map[string]string
+
+	// remote configuration source and its most recently fetched
+	// version, nil if watching files.
+	remote       *config.RemoteSource
+	pollInterval time.Duration
+	remoteConfig []byte
 
 	onceFlag bool
 }
 
-// NewConfigWatcherWorker creates a new watcher on given config file. It reads
+// NewConfigWatcherWorker creates a new watcher on given config file or directory. It reads
 // changes and sends updates to updateChan
 func NewConfigWatcherWorker(configFileName string, updateChan ConfigUpdateChanType, onceFlag bool) *ConfigWatcherWorker {
 	sc := make(chan *sync.WaitGroup, 1)
 	return &ConfigWatcherWorker{
 		StoppableByChan: StoppableByChan{
 			StopChan: &sc,
+			Name:     "configwatcher",
 		},
 		configFileName: configFileName,
 		updateChan:     updateChan,
+		reloadChan:     make(chan chan error),
 		onceFlag:       onceFlag,
 	}
 }
 
+// NewRemoteConfigWatcherWorker creates a new watcher polling a remote configuration
+// source every pollInterval. It reads changes and sends updates to updateChan
+func NewRemoteConfigWatcherWorker(source *config.RemoteSource, pollInterval time.Duration, updateChan ConfigUpdateChanType, onceFlag bool) *ConfigWatcherWorker {
+	res := NewConfigWatcherWorker(source.URL, updateChan, onceFlag)
+	res.remote = source
+	res.pollInterval = pollInterval
+	return res
+}
+
+// ConfigFile returns the name of the watched config file and the
+// modification time of the most recently read version.
+func (s *ConfigWatcherWorker) ConfigFile() (string, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.configFileName, s.lastModTime
+}
+
+// Reload forces the config file to be read and applied, regardless
+// of its modification time. It returns configuration errors, if any.
+func (s *ConfigWatcherWorker) Reload(ctx context.Context) error {
+	resCh := make(chan error, 1)
+	select {
+	case s.reloadChan <- resCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-resCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Worker watches the config file and reads it on changes
 func (s *ConfigWatcherWorker) Worker() {
-	log.Info("configwatcher: Starting Configuration watcher...")
+	logConfigWatcher.Info("configwatcher: Starting Configuration watcher...")
+	defer s.markExited()
+
+	if s.remote != nil {
+		s.watchRemote()
+		return
+	}
 
 	w := watcher.New()
 	w.SetMaxEvents(1)
-	w.FilterOps(watcher.Write, watcher.Create, watcher.Remove)
+	w.FilterOps(watcher.Write, watcher.Create, watcher.Remove, watcher.Rename, watcher.Move)
 
 	if err := w.Add(s.configFileName); err != nil {
-		log.WithField("err", err).Error("configwatcher: Unable to set up watcher")
+		logConfigWatcher.WithField("err", err).Error("configwatcher: Unable to set up watcher")
 	}
 
 	go func() {
@@ -55,42 +123,60 @@ func (s *ConfigWatcherWorker) Worker() {
 		// Trigger artificial event to have config read the first time
 		// we get here.
 		w.Wait()
-		log.Debug("configwatcher: Initial config file read trigger")
+		logConfigWatcher.Debug("configwatcher: Initial config file read trigger")
 		w.TriggerEvent(watcher.Create, nil)
 	}()
 
 	go func() {
 		if err := w.Start(time.Millisecond * 100); err != nil {
-			log.WithField("err", err).Error("configwatcher: Unable to start watcher")
+			logConfigWatcher.WithField("err", err).Error("configwatcher: Unable to start watcher")
 		}
 
 	}()
 
-	log.Debug("configwatcher: Processing file watcher updates.")
+	localNetworkTicker := time.NewTicker(localNetworkCheckInterval)
+	defer localNetworkTicker.Stop()
+
+	logConfigWatcher.Debug("configwatcher: Processing file watcher updates.")
 	for {
 		select {
+		case <-localNetworkTicker.C:
+			if s.localNetworkChanged() {
+				logConfigWatcher.Info("configwatcher: Local interface addresses changed, re-reading config file")
+				s.readConfig()
+			}
 		case event := <-w.Event:
-			log.WithField("e", event).Debug("configwatcher: config file(s) changed")
-			info, err := os.Stat(s.configFileName)
+			logConfigWatcher.WithField("e", event).Debug("configwatcher: config file(s) changed")
+			mt, err := config.LastModified(s.configFileName)
 			if err == nil {
-				mt := info.ModTime()
 				if mt.After(s.lastModTime) {
 					s.readConfig()
-					s.lastModTime = mt
+					s.setLastModTime(mt)
 				}
 			}
 
 			if s.onceFlag {
-				log.Info("configwatcher: Stopping due to --once")
+				logConfigWatcher.Info("configwatcher: Stopping due to --once")
 				return
 			}
+		case resCh := <-s.reloadChan:
+			logConfigWatcher.Info("configwatcher: Forced reload of config file")
+			mt, err := config.LastModified(s.configFileName)
+			if err != nil {
+				resCh <- err
+				break
+			}
+			err = s.readConfig()
+			s.setLastModTime(mt)
+			resCh <- err
+
 		case err := <-w.Error:
-			log.Errorln(err)
+			logConfigWatcher.Errorln(err)
 		case <-w.Closed:
-			log.Info("configwatcher: Stopping Configuratiom Watcher")
+			logConfigWatcher.Info("configwatcher: Stopping Configuratiom Watcher")
 			return
 		case wg := <-*s.StoppableByChan.StopChan:
-			log.Info("configwatcher: Stopping Configuratiom Watcher")
+			logConfigWatcher.Info("configwatcher: Stopping Configuratiom Watcher")
 			wg.Done()
 			return
 		}
@@ -98,65 +184,125 @@ func (s *ConfigWatcherWorker) Worker() {
 
 }
 
-func (s *ConfigWatcherWorker) readConfig() {
-	log.Debug("configwatcher: Reading input file")
+// Loaded returns true if a valid configuration has been read
+func (s *ConfigWatcherWorker) Loaded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// read my config file
-	cfg, err := config.ReadModelFromInput(s.configFileName)
-	if err != nil {
-		log.Error(err)
-		return
-	}
-	log.WithField("cfg", *cfg).Debug("configwatcher: Read config")
+	return s.loaded
+}
 
-	// walk over services, parse spec fields according to plugins
+// NumServices returns the number of services of the most recently
+// loaded configuration
+func (s *ConfigWatcherWorker) NumServices() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	ok := true
-	for _, service := range cfg.Services {
-		spec, err := plugins.ReadPluginSpecByTypeString(service)
-		if err != nil {
-			log.WithField("err", err).Errorf("configwatcher: Unable to parse spec for service %s", service.Name)
-			ok = false
-			continue
-		}
-		log.WithFields(log.Fields{
-			"spec": spec,
-			"name": spec.Name(),
-		}).Trace("configwatcher: service spec")
+	return s.numServices
+}
 
-		spec.Initialize(&cfg.Globals)
-		service.Plugin = spec
+func (s *ConfigWatcherWorker) setLastModTime(mt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	}
-	for _, publisher := range cfg.Publishers {
-		var err error
-		spec, err := plugins.ReadPublisherPluginSpecByTypeString(publisher)
-		if err != nil {
-			log.WithField("err", err).Error("unable to parse spec for publisher %s", publisher.Name)
-			ok = false
-		}
-		log.WithFields(log.Fields{
-			"spec": spec,
-			"name": spec.Name(),
-		}).Trace("configwatcher: publisher spec")
+	s.lastModTime = mt
+}
+
+// readConfig reads and parses the config file, initializes all plugins
+// and passes the new configuration on to the update channel. If the
+// configuration contains errors, it is not applied and the errors are returned.
+func (s *ConfigWatcherWorker) readConfig() error {
+	logConfigWatcher.Debug("configwatcher: Reading input file")
 
-		spec.Initialize(&cfg.Globals)
-		publisher.Plugin = spec
+	// read my config file, or all files of config directory
+	cfg, err := s.readModel()
+	if err != nil {
+		logConfigWatcher.Error(err)
+		emitEvent(EventConfigRejected, "", err.Error(), map[string]string{"file": s.configFileName})
+		metricConfigRejections.Inc()
+		return err
 	}
+	// resolved secrets are part of the config, do not log it
+	logConfigWatcher.WithFields(log.Fields{
+		"numServices":   len(cfg.Services),
+		"numPublishers": len(cfg.Publishers),
+	}).Debug("configwatcher: Read config")
 
-	if !ok {
-		log.Warn("configwatcher: There are configuration errors, will not apply this.")
-		return
+	// local addresses are captured before expanding, so that a change
+	// in between is detected by the next check. They are kept even if
+	// expanding fails, the config is read again when they change.
+	var localAddresses map[string]string
+	if config.UsesLocalNetwork(cfg) {
+		if localAddresses, err = config.LocalNetworkAddresses(); err != nil {
+			logConfigWatcher.WithField("err", err).Warn("configwatcher: Unable to query local interface addresses")
+		}
 	}
+	s.mu.Lock()
+	s.localAddresses = localAddresses
+	s.mu.Unlock()
 
-	// inject refs to globals to all services and publishers
-	for _, service := range cfg.Services {
-		service.Globals = &cfg.Globals
+	// replace ${...} references to parameters
+	if err := config.ExpandParameters(cfg); err != nil {
+		logConfigWatcher.WithField("err", err).Error("configwatcher: Unable to expand parameters")
+		logConfigWatcher.Warn("configwatcher: There are configuration errors, will not apply this.")
+		emitEvent(EventConfigRejected, "", err.Error(), map[string]string{"file": s.configFileName})
+		metricConfigRejections.Inc()
+		return err
 	}
-	for _, publisher := range cfg.Publishers {
-		publisher.Globals = &cfg.Globals
+
+	// walk over services, parse spec fields according to plugins
+	if err := config.InitializePlugins(cfg); err != nil {
+		config.ReleasePlugins(cfg)
+		logConfigWatcher.WithField("err", err).Error("configwatcher: Unable to initialize plugins")
+		logConfigWatcher.Warn("configwatcher: There are configuration errors, will not apply this.")
+		emitEvent(EventConfigRejected, "", err.Error(), map[string]string{"file": s.configFileName})
+		metricConfigRejections.Inc()
+		return err
 	}
 
+	emitEvent(EventConfigRead, "", "Read configuration", map[string]string{
+		"file":          s.configFileName,
+		"numServices":   strconv.Itoa(len(cfg.Services)),
+		"numPublishers": strconv.Itoa(len(cfg.Publishers)),
+	})
+
+	metricConfigReads.Inc()
+
+	s.mu.Lock()
+	s.loaded = true
+	s.numServices = len(cfg.Services)
+	s.mu.Unlock()
+
 	// send new config to update channel
 	s.updateChan <- *cfg
+
+	return nil
+}
+
+// readModel reads the config file or directory, or parses the most
+// recently fetched remote configuration
+func (s *ConfigWatcherWorker) readModel() (*model.IPVSMeshConfig, error) {
+	if s.remote != nil {
+		return s.remote.ReadModel(s.remoteConfig)
+	}
+	return config.ReadModel(s.configFileName)
+}
+
+// localNetworkChanged returns true if the current configuration references
+// local interface addresses, and these have changed since it has been read.
+func (s *ConfigWatcherWorker) localNetworkChanged() bool {
+	s.mu.Lock()
+	current := s.localAddresses
+	s.mu.Unlock()
+
+	if current == nil {
+		return false
+	}
+
+	addresses, err := config.LocalNetworkAddresses()
+	if err != nil {
+		logConfigWatcher.WithField("err", err).Warn("configwatcher: Unable to query local interface addresses")
+		return false
+	}
+	return !reflect.DeepEqual(current, addresses)
 }
@@ -3,23 +3,41 @@ package daemon
 import (
 	"context"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/aschmidt75/ipvsmesh/config"
 	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"github.com/aschmidt75/ipvsmesh/systemd"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 // StoppableByChan is a service part that can
 // be stopped by sending to given channel
 type StoppableByChan struct {
 	StopChan *chan *sync.WaitGroup
+
+	// Name identifies the worker in health checks
+	Name string
+
+	// set to 1 when the worker loop has returned
+	exited int32
+}
+
+// markExited records that the worker loop has returned
+func (s *StoppableByChan) markExited() {
+	atomic.StoreInt32(&s.exited, 1)
+}
+
+// Alive returns true unless the worker loop has returned
+func (s *StoppableByChan) Alive() bool {
+	return atomic.LoadInt32(&s.exited) == 0
 }
 
 // Service is a service that is able to stop
@@ -28,9 +46,24 @@ type Service struct {
 	StoppableByChan
 	GroupID    int
 	grpcServer *grpc.Server
+	httpServer *http.Server
+
+	// workers queried and controlled by grpc calls
+	ConfigWatcher *ConfigWatcherWorker
+	ConfigApplier *ConfigApplierWorker
+	IPVSApplier   *IPVSApplierWorker
+	Publisher     *PublisherhWorker
 
 	registeredStoppables []*StoppableByChan
 	wg                   sync.WaitGroup
+	stopOnce             sync.Once
+	startTime            time.Time
+
+	// closed when shutting down, ends streaming calls
+	stopping chan struct{}
+
+	// true if the unix socket has been passed by systemd
+	socketActivated bool
 }
 
 // NewService creates a new instance of the stoppable daemon service
@@ -40,22 +73,41 @@ func NewService(groupID int) *Service {
 		StoppableByChan: StoppableByChan{
 			StopChan: &sc,
 		},
-		GroupID: groupID,
+		GroupID:   groupID,
+		startTime: time.Now(),
+		stopping:  make(chan struct{}),
 	}
 }
 
 // Stop stops all running services
 func (s *Service) Stop(context.Context, *localinterface.Empty) (*localinterface.Empty, error) {
+	s.stopWorkers()
+
+	s.wg.Add(1)
+	*s.StopChan <- &s.wg
+
+	return &localinterface.Empty{}, nil
+}
+
+// stopWorkers stops all registered workers in reverse order and waits for
+// them, at most for the default timeout plus the time to drain services.
+// Workers are only stopped once, so that a signal following a stop call
+// does not block.
+func (s *Service) stopWorkers() {
+	s.stopOnce.Do(s.doStopWorkers)
+}
+
+func (s *Service) doStopWorkers() {
 	var timeoutSecs int = config.Config().DefaultTimeout
+	if s.IPVSApplier != nil {
+		// leave time to drain services
+		timeoutSecs += int(s.IPVSApplier.ShutdownPeriod() / time.Second)
+	}
 	log.WithField("timeoutSecs", timeoutSecs).Info("daemon: Stopping workers...")
 
 	// take down in reverse order
-	for l, r := 0, len(s.registeredStoppables)-1; l < r; l, r = l+1, r-1 {
-		s.registeredStoppables[l], s.registeredStoppables[r] = s.registeredStoppables[r], s.registeredStoppables[l]
-	}
-
-	for _, r := range s.registeredStoppables {
-		if r != nil {
+	for idx := len(s.registeredStoppables) - 1; idx >= 0; idx-- {
+		if r := s.registeredStoppables[idx]; r != nil {
 			*r.StopChan <- &s.wg
 		}
 	}
@@ -70,11 +122,6 @@ func (s *Service) Stop(context.Context, *localinterface.Empty) (*localinterface.
 	select {
 	case <-ctxTO.Done():
 	}
-
-	s.wg.Add(1)
-	*s.StopChan <- &s.wg
-
-	return &localinterface.Empty{}, nil
 }
 
 // Register adds a new StoppableByChan to the list of registered services
@@ -86,6 +133,86 @@ func (s *Service) Register(stoppable *StoppableByChan) {
 	s.wg.Add(1)
 }
 
+// listen creates the listener on the unix socket and the optional TCP listener
+// for remote management. If sockets have been passed by systemd socket activation,
+// the first unix socket is used instead, all others are used for remote management.
+func (s *Service) listen() (net.Listener, []net.Listener) {
+	remote := make([]net.Listener, 0)
+
+	if systemd.ListenFDs() > 0 {
+		activated, err := systemd.Listeners()
+		if err != nil {
+			log.WithField("err", err).Error("daemon: Unable to use socket-activated listeners.")
+			os.Exit(3)
+		}
+
+		var listener net.Listener
+		for _, l := range activated {
+			if listener == nil && l.Addr().Network() == "unix" {
+				listener = l
+				continue
+			}
+			remote = append(remote, l)
+		}
+		if listener == nil {
+			log.Error("daemon: No unix socket passed by socket activation.")
+			os.Exit(3)
+		}
+		log.WithField("socket", listener.Addr()).Info("daemon: Using socket-activated listener")
+		s.socketActivated = true
+
+		return listener, remote
+	}
+
+	log.Trace("daemon: creating listener/socket file")
+
+	log.WithFields(log.Fields{"pid": os.Getpid(), "uid": syscall.Getuid(), "gid": syscall.Getgid()}).Trace("daemon")
+	syscall.Umask(int(0007)) // new files be rwxrwx---
+
+	//
+	listener, err := net.Listen("unix", config.Config().DaemonSocketPath)
+	if err != nil {
+		log.WithField("err", err).Error("daemon: Unable to listen on unix socket.")
+		os.Exit(3)
+	}
+
+	// change ownership to group (if group given, != -1)
+	if err := os.Chown(config.Config().DaemonSocketPath, -1, s.GroupID); err != nil {
+		log.WithField("err", err).Warn("daemon: unable to chgrp for socket file.")
+	}
+
+	// optional TCP listener for remote management
+	if addr := config.Config().DaemonListenAddress; addr != "" {
+		tcpListener, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.WithField("err", err).Error("daemon: Unable to listen on TCP address.")
+			os.Exit(3)
+		}
+		remote = append(remote, tcpListener)
+	}
+
+	return listener, remote
+}
+
+// serveTCP serves the grpc service on a TCP listener in addition
+// to the unix socket. This requires TLS with verified client certificates.
+func (s *Service) serveTCP(listener net.Listener) {
+	if !config.Config().TLS {
+		log.WithField("listen", listener.Addr()).Fatal("daemon: Listening on TCP requires --tls.")
+	}
+	if config.Config().TLSCAFile == "" {
+		log.WithField("listen", listener.Addr()).Fatal("daemon: Listening on TCP requires --tlsca to verify client certificates.")
+	}
+	log.WithField("listen", listener.Addr()).Info("daemon: Listening on TCP")
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			log.WithField("err", err).Error("Error serving grpc backend on TCP.")
+			os.Exit(4)
+		}
+	}()
+}
+
 // Start starts the background service
 func (s *Service) Start(ctx context.Context) {
 	log.Info("Starting services...")
@@ -94,70 +221,89 @@ func (s *Service) Start(ctx context.Context) {
 	signal.Notify(term, syscall.SIGTERM)
 	signal.Notify(term, os.Interrupt)
 
-	// start grpc stuff, kick to bgnd
-	go func() {
-		log.Trace("daemon: creating listener/socket file")
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 
-		log.WithFields(log.Fields{"pid": os.Getpid(), "uid": syscall.Getuid(), "gid": syscall.Getgid()}).Trace("daemon")
-		syscall.Umask(int(0007)) // new files be rwxrwx---
+	listener, remote := s.listen()
 
-		//
-		listener, err := net.Listen("unix", config.Config().DaemonSocketPath)
-		if err != nil {
-			log.WithField("err", err).Error("daemon: Unable to listen on unix socket.")
-			os.Exit(3)
-		}
+	// start grpc stuff, kick to bgnd
+	go func() {
+		log.WithField("listener", listener).Trace("daemon: created listener, registering grpc service")
 
-		// change ownership to group (if group given, != -1)
-		if err := os.Chown(config.Config().DaemonSocketPath, -1, s.GroupID); err != nil {
-			log.WithField("err", err).Warn("daemon: unable to chgrp for socket file.")
+		opts := []grpc.ServerOption{
+			grpc.UnaryInterceptor(logUnaryCall),
+			grpc.StreamInterceptor(logStreamCall),
 		}
 
-		log.WithField("listener", listener).Trace("daemon: created listener, registering grpc service")
-
 		if config.Config().TLS {
 			if config.Config().TLSKeyFile == "" {
 				log.Fatal("Missing --tlskey. Please supply when using --tls.")
 			}
 
-			creds, err := credentials.NewServerTLSFromFile(config.Config().TLSCertFile, config.Config().TLSKeyFile)
+			creds, err := newServerCredentials(config.Config().TLSCertFile, config.Config().TLSKeyFile, config.Config().TLSCAFile)
 			if err != nil {
-				log.Fatal("unable to load TLS key or certificate from file. Check --tls* parameters.")
+				log.WithField("err", err).Fatal("unable to load TLS key, certificate or CA from file. Check --tls* parameters.")
+			}
+			if config.Config().TLSCAFile != "" {
+				log.WithField("ca", config.Config().TLSCAFile).Info("daemon: Requiring client certificates")
 			}
 
-			// Create an array of gRPC options with the credentials
-			opts := []grpc.ServerOption{grpc.Creds(creds)}
-
-			s.grpcServer = grpc.NewServer(opts...)
-
-		} else {
-			s.grpcServer = grpc.NewServer()
+			// Add the credentials to the gRPC options
+			opts = append(opts, grpc.Creds(creds))
 		}
 
+		s.grpcServer = grpc.NewServer(opts...)
+
 		localinterface.RegisterDaemonServiceServer(s.grpcServer, s)
 
+		for _, l := range remote {
+			s.serveTCP(l)
+		}
+
 		log.WithField("grpcServer", s.grpcServer).Trace("daemon: start serving grpc")
 
-		err = s.grpcServer.Serve(listener)
+		err := s.grpcServer.Serve(listener)
 		if err != nil {
 			log.WithField("err", err).Error("Error serving grpc backend.")
 			os.Exit(4)
 		}
 	}()
 
-	select {
-	case <-term:
-	case <-*s.StopChan:
+	// optional health endpoints
+	if addr := config.Config().HTTPListenAddress; addr != "" {
+		s.serveHTTP(addr)
+	}
+
+	go s.notifySystemd()
+
+loop:
+	for {
+		select {
+		case <-hup:
+			log.Info("daemon: SIGHUP received, reloading configuration")
+			go s.reloadOnSignal()
+		case sig := <-term:
+			// apply the shutdown policy as on a stop call
+			log.WithField("signal", sig).Info("daemon: Signal received, stopping")
+			s.stopWorkers()
+			break loop
+		case <-*s.StopChan:
+			break loop
+		}
 	}
 
 	//
+	sdNotify(systemd.Stopping)
+	close(s.stopping)
+	s.stopHTTP()
 	if s.grpcServer != nil {
 		s.grpcServer.GracefulStop()
 	}
 
-	// remove socket file if its still there.
+	// remove socket file if its still there. If systemd passed
+	// the socket, it is not ours to remove.
 	_, err := os.Stat(config.Config().DaemonSocketPath)
-	if err == nil {
+	if err == nil && !s.socketActivated {
 		log.Trace("daemon: removing socket file")
 		err := os.Remove(config.Config().DaemonSocketPath)
 		if err != nil {
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/aschmidt75/ipvsmesh/config"
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DiffConfig builds the ipvsctl model a candidate configuration would produce,
+// by querying plugins with the candidate specs, and compares it to the currently
+// applied model. Nothing is applied. References to environment variables and
+// secret files as well as parameters are resolved by the client, candidates
+// containing them are rejected.
+func (s *Service) DiffConfig(ctx context.Context, req *localinterface.DiffRequest) (*localinterface.DiffResponse, error) {
+	if s.IPVSApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no ipvs applier active")
+	}
+
+	res := &localinterface.DiffResponse{
+		Changes:  make([]*localinterface.ModelChange, 0),
+		Errors:   make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+
+	cfg, err := config.ReadModelFromBytes(req.Config)
+	if err != nil {
+		res.Errors = errorStrings(err)
+		return res, nil
+	}
+	if err := config.CheckNoReferences(cfg); err != nil {
+		res.Errors = errorStrings(err)
+		return res, nil
+	}
+	if len(cfg.Parameters) > 0 {
+		res.Errors = append(res.Errors, "candidate must not contain parameters, they are expanded by the client")
+		return res, nil
+	}
+	// the candidate is never run, release its plugins when done
+	defer config.ReleasePlugins(cfg)
+	if err := config.InitializePlugins(cfg); err != nil {
+		res.Errors = errorStrings(err)
+		return res, nil
+	}
+
+	// runtime services are part of the candidate as well
+	if s.ConfigApplier != nil {
+		merged := s.ConfigApplier.WithRuntimeServices(*cfg)
+		cfg = &merged
+	}
+
+	candidate, warnings := s.IPVSApplier.CandidateModel(cfg)
+	for _, w := range warnings {
+		res.Warnings = append(res.Warnings, w.Error())
+	}
+
+	for _, c := range s.IPVSApplier.AppliedModel().Diff(candidate) {
+		res.Changes = append(res.Changes, &localinterface.ModelChange{
+			Kind:        string(c.Kind),
+			Service:     c.Service,
+			ServiceName: c.ServiceName,
+			Destination: c.Destination,
+			Field:       c.Field,
+			Old:         c.Old,
+			New:         c.New,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EventType classifies events emitted by the daemon
+type EventType string
+
+// Event types emitted by the workers of the daemon
+const (
+	EventConfigRead           EventType = "config.read"
+	EventConfigRejected       EventType = "config.rejected"
+	EventServiceWorkerStarted EventType = "serviceworker.started"
+	EventServiceWorkerStopped EventType = "serviceworker.stopped"
+	EventServiceWorkerUpdated EventType = "serviceworker.updated"
+	EventBackendAdded         EventType = "backend.added"
+	EventBackendRemoved       EventType = "backend.removed"
+	EventBackendReweighted    EventType = "backend.reweighted"
+	EventBackendDrained       EventType = "backend.drained"
+	EventBackendEnabled       EventType = "backend.enabled"
+	EventApplySucceeded       EventType = "apply.succeeded"
+	EventApplyFailed          EventType = "apply.failed"
+	EventApplyPaused          EventType = "apply.paused"
+	EventApplyResumed         EventType = "apply.resumed"
+	EventPublishSucceeded     EventType = "publish.succeeded"
+	EventPublishFailed        EventType = "publish.failed"
+)
+
+// Event is something that happened inside the daemon, optionally
+// related to a service.
+type Event struct {
+	Time    time.Time
+	Type    EventType
+	Service string
+	Message string
+	Fields  map[string]string
+}
+
+// EventBus distributes events to all subscribers. It keeps
+// a backlog of the most recent events for new subscribers.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	backlog     []Event
+	maxBacklog  int
+}
+
+const (
+	eventBacklogSize    = 100
+	eventSubscriberSize = 64
+)
+
+var (
+	eventBus *EventBus
+	ebOnce   sync.Once
+)
+
+// GetEventBus returns the event bus of this daemon
+func GetEventBus() *EventBus {
+	ebOnce.Do(func() {
+		eventBus = &EventBus{
+			subscribers: make(map[chan Event]struct{}),
+			backlog:     make([]Event, 0, eventBacklogSize),
+			maxBacklog:  eventBacklogSize,
+		}
+	})
+	return eventBus
+}
+
+// Publish sends an event to all subscribers. It does not block, slow
+// subscribers miss events.
+func (b *EventBus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.backlog) >= b.maxBacklog {
+		b.backlog = b.backlog[1:]
+	}
+	b.backlog = append(b.backlog, e)
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.WithField("type", e.Type).Trace("events: Subscriber too slow, dropping event")
+		}
+	}
+}
+
+// Subscribe registers a new subscriber. It returns a channel receiving
+// all future events, and a copy of the current backlog.
+func (b *EventBus) Subscribe() (chan Event, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, eventSubscriberSize)
+	b.subscribers[ch] = struct{}{}
+
+	backlog := make([]Event, len(b.backlog))
+	copy(backlog, b.backlog)
+
+	return ch, backlog
+}
+
+// Unsubscribe removes a subscriber channel
+func (b *EventBus) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, ch)
+}
+
+// emitEvent publishes an event on the daemon's event bus
+func emitEvent(t EventType, service string, msg string, fields map[string]string) {
+	GetEventBus().Publish(Event{
+		Type:    t,
+		Service: service,
+		Message: msg,
+		Fields:  fields,
+	})
+}
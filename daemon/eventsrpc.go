@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	log "github.com/sirupsen/logrus"
+)
+
+func newEventMessage(e Event) *localinterface.Event {
+	return &localinterface.Event{
+		Time:    e.Time.UnixNano(),
+		Type:    string(e.Type),
+		Service: e.Service,
+		Message: e.Message,
+		Fields:  e.Fields,
+	}
+}
+
+// Events streams the backlog of recent events and, if requested, all
+// subsequent events until the client disconnects.
+func (s *Service) Events(req *localinterface.EventsRequest, stream localinterface.DaemonService_EventsServer) error {
+	ch, backlog := GetEventBus().Subscribe()
+	defer GetEventBus().Unsubscribe(ch)
+
+	for _, e := range backlog {
+		if req.Service != "" && e.Service != req.Service {
+			continue
+		}
+		if err := stream.Send(newEventMessage(e)); err != nil {
+			return err
+		}
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	log.WithField("service", req.Service).Debug("daemon: Streaming events to client")
+	for {
+		select {
+		case e := <-ch:
+			if req.Service != "" && e.Service != req.Service {
+				continue
+			}
+			if err := stream.Send(newEventMessage(e)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			log.Debug("daemon: Event client disconnected")
+			return nil
+		case <-s.stopping:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+)
+
+// ComponentProblem describes a component failing a health or readiness check
+type ComponentProblem struct {
+	Component string `json:"component"`
+	Reason    string `json:"reason"`
+}
+
+// HealthResponse is the result of a health or readiness check
+type HealthResponse struct {
+	Status  string             `json:"status"` // ok or failing
+	Failing []ComponentProblem `json:"failing"`
+}
+
+func newHealthResponse(problems []ComponentProblem) HealthResponse {
+	if len(problems) == 0 {
+		return HealthResponse{Status: "ok", Failing: problems}
+	}
+	return HealthResponse{Status: "failing", Failing: problems}
+}
+
+// Liveness checks that all registered workers and all service workers are
+// running, including the notification loops of their plugins.
+func (s *Service) Liveness() HealthResponse {
+	problems := make([]ComponentProblem, 0)
+
+	for _, r := range s.registeredStoppables {
+		if r != nil && !r.Alive() {
+			problems = append(problems, ComponentProblem{Component: r.Name, Reason: "worker is not running"})
+		}
+	}
+
+	for _, sws := range GetServiceWorkerStatus() {
+		component := "serviceworker:" + sws.Name
+		if !sws.Alive {
+			problems = append(problems, ComponentProblem{Component: component, Reason: "worker is not running"})
+			continue
+		}
+		if sws.HasNotificationLoop && !sws.NotificationLoopRunning {
+			reason := "notification loop of plugin is not running"
+			if sws.NotificationLoopErr != nil {
+				reason = fmt.Sprintf("%s: %s", reason, sws.NotificationLoopErr)
+			}
+			problems = append(problems, ComponentProblem{Component: component, Reason: reason})
+		}
+	}
+
+	return newHealthResponse(problems)
+}
+
+// Readiness checks that a valid configuration has been loaded and
+// that at least one ipvsctl apply has been successful. A configuration
+// without services has nothing to apply, it is ready once loaded.
+func (s *Service) Readiness() HealthResponse {
+	loaded, numServices := false, 0
+	if s.ConfigWatcher != nil {
+		loaded, numServices = s.ConfigWatcher.Loaded(), s.ConfigWatcher.NumServices()
+	}
+	var lastSuccess time.Time
+	if s.IPVSApplier != nil {
+		lastSuccess = s.IPVSApplier.LastSuccessfulApply()
+	}
+
+	return newHealthResponse(readinessProblems(loaded, numServices, lastSuccess))
+}
+
+// readinessProblems returns the reasons for not being ready
+func readinessProblems(loaded bool, numServices int, lastSuccess time.Time) []ComponentProblem {
+	problems := make([]ComponentProblem, 0)
+
+	if !loaded {
+		problems = append(problems, ComponentProblem{Component: "configwatcher", Reason: "no valid configuration loaded"})
+	} else if numServices == 0 {
+		return problems
+	}
+	if lastSuccess.IsZero() {
+		problems = append(problems, ComponentProblem{Component: "ipvsapplier", Reason: "no successful apply yet"})
+	}
+
+	return problems
+}
@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadinessProblems(t *testing.T) {
+	tests := []struct {
+		name        string
+		loaded      bool
+		numServices int
+		lastSuccess time.Time
+		failing     []string // failing components
+	}{
+		{"nothing loaded", false, 0, time.Time{}, []string{"configwatcher", "ipvsapplier"}},
+		{"loaded, not applied", true, 2, time.Time{}, []string{"ipvsapplier"}},
+		{"loaded and applied", true, 2, time.Now(), []string{}},
+		{"loaded without services", true, 0, time.Time{}, []string{}},
+	}
+	for _, test := range tests {
+		problems := readinessProblems(test.loaded, test.numServices, test.lastSuccess)
+		if len(problems) != len(test.failing) {
+			t.Errorf("%s: got %v, expected %v failing", test.name, problems, test.failing)
+			continue
+		}
+		for idx, p := range problems {
+			if p.Component != test.failing[idx] {
+				t.Errorf("%s: got %s failing, expected %s", test.name, p.Component, test.failing[idx])
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// serveHTTP starts an HTTP server on addr, exposing /healthz, /readyz and /metrics
+func (s *Service) serveHTTP(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, s.Liveness())
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, s.Readiness())
+	})
+	mux.Handle("/metrics", promhttp.Handler())
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.WithField("err", err).Error("daemon: Unable to listen on HTTP address.")
+		os.Exit(3)
+	}
+	log.WithField("listen", listener.Addr()).Info("daemon: Serving health and metrics endpoints")
+
+	s.httpServer = &http.Server{Handler: mux}
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithField("err", err).Error("daemon: Error serving HTTP.")
+		}
+	}()
+}
+
+// stopHTTP shuts down the HTTP server, if running
+func (s *Service) stopHTTP() {
+	if s.httpServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		log.WithField("err", err).Warn("daemon: Unable to shut down HTTP server.")
+	}
+}
+
+// writeHealth writes a health response as JSON, with status 503 if failing
+func writeHealth(w http.ResponseWriter, h HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if h.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(h); err != nil {
+		log.WithField("err", err).Debug("daemon: Unable to write health response")
+	}
+}
@@ -6,13 +6,19 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
 
+var logIPVSApplier = logging.Component("ipvsapplier")
+
 // IPVSApplierUpdateStruct is a message from a plugin with a downward api.
 // The message affects a single service from the model and contains data updates.
 type IPVSApplierUpdateStruct struct {
@@ -39,6 +45,31 @@ type IPVSApplierWorker struct {
 	// remember all updates we received
 	services map[string]IPVSApplierUpdateStruct
 	mu       sync.Mutex
+
+	// most recent backends per service, survives cache flushes. Used to detect changes.
+	lastBackends map[string][]Backend
+
+	// runtime weight overrides by service and backend address, survive cache flushes
+	overrides   map[string]map[string]BackendOverride
+	reapplyChan chan struct{}
+
+	lastApply    ApplyResult
+	lastSuccess  time.Time
+	appliedModel model.IPVSModelStruct
+
+	// when paused, updates are integrated but not applied
+	paused      bool
+	pausedSince time.Time
+	pending     bool
+}
+
+// ApplyResult describes the outcome of an ipvsctl apply run
+type ApplyResult struct {
+	Time        time.Time
+	Err         error
+	ExecType    string
+	Duration    time.Duration
+	NumServices int
 }
 
 // NewIPVSApplierWorker creates an IPVS applier worker based on
@@ -49,14 +80,51 @@ func NewIPVSApplierWorker(updateChan IPVSApplierChanType, publisherUpdateChan Pu
 	return &IPVSApplierWorker{
 		StoppableByChan: StoppableByChan{
 			StopChan: &sc,
+			Name:     "ipvsapplier",
 		},
 		updateChan:          updateChan,
 		publisherUpdateChan: publisherUpdateChan,
 		cfg:                 nil,
 		services:            make(map[string]IPVSApplierUpdateStruct, 5),
+		lastBackends:        make(map[string][]Backend, 5),
+		overrides:           make(map[string]map[string]BackendOverride, 5),
+		reapplyChan:         make(chan struct{}, 1),
 	}
 }
 
+// ServiceBackends is a snapshot of a single service as known to the
+// ipvs applier, with defaults and effective backend weights resolved.
+type ServiceBackends struct {
+	Name    string
+	Address string
+	Type    string
+	Runtime bool
+	Source  string
+
+	// options with defaults resolved
+	model.ServiceOptions
+
+	Backends []Backend
+}
+
+// Backend is a single real server of a service with its effective weight
+// and connection thresholds
+type Backend struct {
+	Address        string
+	Weight         int
+	UpperThreshold int
+	LowerThreshold int
+	AdditionalInfo map[string]string
+
+	// Override is set if a runtime override is active for this backend
+	Override *BackendOverride
+}
+
+// Removed returns true if the backend is not to be part of the ipvsctl model
+func (b Backend) Removed(now time.Time) bool {
+	return b.Override != nil && b.Override.Removed(now)
+}
+
 // integrates an update from the downward api into the current overall model and
 // produces an IPVS ctl conformant model
 func (s *IPVSApplierWorker) integrateUpdate(u IPVSApplierUpdateStruct) (map[string]interface{}, error) {
@@ -66,74 +134,279 @@ func (s *IPVSApplierWorker) integrateUpdate(u IPVSApplierUpdateStruct) (map[stri
 	// this is the current model/config we're operating on
 	s.cfg = u.cfg
 
-	// fill in sane defaults. TODO: Refactor to global defaults struct
-	w := u.service.Weight
-	if w == 0 {
-		w = 1000 // TODO: Defaults
+	// copy update into own cached model
+	s.services[u.serviceName] = u
+
+	if u.service != nil {
+		backends := resolveService(u, s.overrides[u.serviceName]).Backends
+		emitBackendEvents(u.serviceName, s.lastBackends[u.serviceName], backends)
+		s.lastBackends[u.serviceName] = backends
 	}
-	sched := u.service.SchedName
-	if sched == "" {
-		sched = "wrr"
+
+	return s.buildModel(), nil
+}
+
+// emitBackendEvents compares previous and current backends of a service
+// and emits events for added, removed and reweighted backends.
+func emitBackendEvents(serviceName string, prev, cur []Backend) {
+	prevByAddress := make(map[string]Backend, len(prev))
+	for _, b := range prev {
+		prevByAddress[b.Address] = b
 	}
-	forward := u.service.Forward
-	if forward == "" {
-		forward = "nat"
+
+	for _, b := range cur {
+		pb, ex := prevByAddress[b.Address]
+		if !ex {
+			emitEvent(EventBackendAdded, serviceName, "Backend added", map[string]string{
+				"address": b.Address,
+				"weight":  strconv.Itoa(b.Weight),
+			})
+		} else if pb.Weight != b.Weight {
+			emitEvent(EventBackendReweighted, serviceName, "Backend reweighted", map[string]string{
+				"address":   b.Address,
+				"weight":    strconv.Itoa(b.Weight),
+				"oldWeight": strconv.Itoa(pb.Weight),
+			})
+		}
+		delete(prevByAddress, b.Address)
 	}
 
-	// copy update into own cached model
-	s.services[u.serviceName] = u
+	for _, pb := range prevByAddress {
+		emitEvent(EventBackendRemoved, serviceName, "Backend removed", map[string]string{
+			"address": pb.Address,
+		})
+	}
+}
 
+// resolveService fills in defaults and computes effective weights
+// for a cached service update, including runtime overrides.
+func resolveService(u IPVSApplierUpdateStruct, overrides map[string]BackendOverride) ServiceBackends {
+	service := u.service.Effective()
+
+	res := ServiceBackends{
+		Name:           service.Name,
+		Address:        service.Address,
+		Type:           service.Type,
+		Runtime:        service.Runtime,
+		Source:         service.Source,
+		ServiceOptions: service.ServiceOptions,
+		Backends:       make([]Backend, len(u.data)),
+	}
+	for idx, downwardBackendServer := range u.data {
+		bw := service.Weight
+		// adjust weight in case of dynamic weights
+		if downwardBackendServer.Weight >= 0 {
+			bw = downwardBackendServer.Weight
+		}
+		res.Backends[idx] = Backend{
+			Address:        downwardBackendServer.Address,
+			Weight:         bw,
+			UpperThreshold: service.UpperThreshold,
+			LowerThreshold: service.LowerThreshold,
+			AdditionalInfo: downwardBackendServer.AdditionalInfo,
+		}
+		if downwardBackendServer.UpperThreshold > 0 {
+			res.Backends[idx].UpperThreshold = downwardBackendServer.UpperThreshold
+		}
+		if downwardBackendServer.LowerThreshold > 0 {
+			res.Backends[idx].LowerThreshold = downwardBackendServer.LowerThreshold
+		}
+		if o, ex := overrides[downwardBackendServer.Address]; ex {
+			res.Backends[idx].Weight = o.Weight
+			res.Backends[idx].Override = &o
+		}
+	}
+	return res
+}
+
+// snapshot returns all cached services, sorted by name. Caller must hold s.mu
+func (s *IPVSApplierWorker) snapshot() []ServiceBackends {
+	return resolveServices(s.services, s.overrides)
+}
+
+// resolveServices resolves all given service updates, sorted by name
+func resolveServices(services map[string]IPVSApplierUpdateStruct, overrides map[string]map[string]BackendOverride) []ServiceBackends {
+	res := make([]ServiceBackends, 0, len(services))
+	for _, u := range services {
+		if u.service == nil {
+			continue
+		}
+		res = append(res, resolveService(u, overrides[u.serviceName]))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Services returns a snapshot of all services and their backends
+// as they are currently known to the applier.
+func (s *IPVSApplierWorker) Services() []ServiceBackends {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot()
+}
+
+// buildModel creates an ipvsctl model from all cached services. Caller must hold s.mu
+func (s *IPVSApplierWorker) buildModel() model.IPVSModelStruct {
+	services := s.snapshot()
+	updateServiceMetrics(services)
+	return buildModel(services)
+}
+
+// buildModel creates an ipvsctl model from resolved services
+func buildModel(services []ServiceBackends) model.IPVSModelStruct {
 	// recreate target. IPVSModelStruct is map[string]interface{}, so
 	// here we're creating maps on the fly as the basis for a ipvsctl yaml file.
 	var target model.IPVSModelStruct
 	target = make(model.IPVSModelStruct, 5)
 
-	// count services with non-empty destinations list
-	numNonEmptyServices := 0
-	for _, service := range s.services {
-		if len(service.data) > 0 {
-			numNonEmptyServices++
+	now := time.Now()
+	tss := make([]interface{}, 0, len(services))
+	for _, service := range services {
+		// skip backends which have been removed by an override
+		backends := make([]Backend, 0, len(service.Backends))
+		for _, backend := range service.Backends {
+			if !backend.Removed(now) {
+				backends = append(backends, backend)
+			}
 		}
-	}
 
-	tss := make([]interface{}, numNonEmptyServices)
-	target["services"] = tss
-
-	idx := 0
-	for _, service := range s.services {
-		if len(service.data) == 0 {
+		// skip services with empty destinations list
+		if len(backends) == 0 {
 			continue
 		}
 
 		ts := make(map[string]interface{})
-		tss[idx] = ts
-		ts["address"] = u.service.Address
-		ts["ipvsmesh.service.name"] = u.service.Name
-		ts["ipvsmesh.service.type"] = u.service.Type
-		ts["sched"] = sched
-
-		td := make([]interface{}, len(service.data))
-		ts["destinations"] = td
-		for idx, downwardBackendServer := range service.data {
-			// adjust weight in case of dynamic weights
-			if downwardBackendServer.Weight >= 0 {
-				w = downwardBackendServer.Weight
+		ts["address"] = service.Address
+		ts["ipvsmesh.service.name"] = service.Name
+		ts["ipvsmesh.service.type"] = service.Type
+		ts["sched"] = service.SchedName
+		if len(service.SchedFlags) > 0 {
+			ts["schedFlags"] = service.SchedFlags
+		}
+		if service.PersistenceTimeout > 0 {
+			ts["persistenceTimeout"] = service.PersistenceTimeout
+			if service.Netmask != "" {
+				ts["netmask"] = service.Netmask
 			}
+		}
+		if service.IsOnePacket() {
+			ts["onePacket"] = true
+		}
 
+		td := make([]interface{}, len(backends))
+		ts["destinations"] = td
+		for idx, backend := range backends {
 			tdd := make(map[string]interface{}, 3)
 			td[idx] = tdd
-			tdd["address"] = downwardBackendServer.Address
-			tdd["forward"] = forward
-			tdd["weight"] = w
-			for k, v := range downwardBackendServer.AdditionalInfo {
+			tdd["address"] = backend.Address
+			tdd["forward"] = service.Forward
+			tdd["weight"] = backend.Weight
+			if backend.UpperThreshold > 0 {
+				tdd["upperThreshold"] = backend.UpperThreshold
+			}
+			if backend.LowerThreshold > 0 {
+				tdd["lowerThreshold"] = backend.LowerThreshold
+			}
+			for k, v := range backend.AdditionalInfo {
 				tdd[fmt.Sprintf("ipvsmesh.%s", k)] = v
 			}
 		}
 
-		idx++
+		tss = append(tss, ts)
+	}
+	target["services"] = tss
+
+	return target
+}
+
+// execType returns the configured ipvsctl execution type
+func (s *IPVSApplierWorker) execType() string {
+	if s.cfg == nil || s.cfg.Globals.Ipvsctl.ExecType == "" {
+		return "exec-only"
+	}
+	return s.cfg.Globals.Ipvsctl.ExecType
+}
+
+// LastApply returns the outcome of the most recent applyUpdate
+func (s *IPVSApplierWorker) LastApply() ApplyResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastApply
+}
+
+func (s *IPVSApplierWorker) recordApply(start time.Time, target map[string]interface{}, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numServices := 0
+	if tss, ok := target["services"].([]interface{}); ok {
+		numServices = len(tss)
+	}
+
+	s.lastApply = ApplyResult{
+		Time:        start,
+		Err:         err,
+		ExecType:    s.execType(),
+		Duration:    time.Since(start),
+		NumServices: numServices,
+	}
+	metricApplyDuration.WithLabelValues(s.lastApply.ExecType).Observe(s.lastApply.Duration.Seconds())
+	if err == nil {
+		s.appliedModel = target
+		s.lastSuccess = start
+	} else {
+		metricApplyFailures.WithLabelValues(s.lastApply.ExecType).Inc()
+	}
+}
+
+// LastSuccessfulApply returns the time of the most recent successful
+// apply, or the zero time if nothing has been applied yet.
+func (s *IPVSApplierWorker) LastSuccessfulApply() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSuccess
+}
+
+// AppliedModel returns the most recently successfully applied ipvsctl model
+func (s *IPVSApplierWorker) AppliedModel() model.IPVSModelStruct {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.appliedModel == nil {
+		return model.IPVSModelStruct{"services": []interface{}{}}
+	}
+	return s.appliedModel
+}
+
+// CandidateModel queries the plugins of all services of cfg once and returns
+// the ipvsctl model that would result from cfg, including active overrides.
+// Nothing is applied. Errors from plugins are returned alongside the model.
+func (s *IPVSApplierWorker) CandidateModel(cfg *model.IPVSMeshConfig) (model.IPVSModelStruct, []error) {
+	errs := make([]error, 0)
+
+	services := make(map[string]IPVSApplierUpdateStruct, len(cfg.Services))
+	for _, service := range cfg.Services {
+		data, err := service.Plugin.GetDownwardData()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to get downward data for service %s: %s", service.Name, err))
+		}
+		sort.Sort(byAddress(data))
+
+		services[service.Name] = IPVSApplierUpdateStruct{
+			cfg:         cfg,
+			serviceName: service.Name,
+			service:     service,
+			data:        data,
+		}
 	}
 
-	return target, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return buildModel(resolveServices(services, s.overrides)), errs
 }
 
 // applyUpdate takes an ipvsctl-conformant im-memory struct and passes
@@ -144,13 +417,10 @@ func (s *IPVSApplierWorker) applyUpdate(target map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
-	log.WithField("yaml", string(b)).Trace("ipvsapplier: Applying ipvsctl model")
-	log.Debug("ipvsapplier: Applying ipvsctl model")
+	logIPVSApplier.WithField("yaml", string(b)).Trace("ipvsapplier: Applying ipvsctl model")
+	logIPVSApplier.Debug("ipvsapplier: Applying ipvsctl model")
 
-	execType := s.cfg.Globals.Ipvsctl.ExecType
-	if execType == "" {
-		execType = "exec-only"
-	}
+	execType := s.execType()
 	fileName := s.cfg.Globals.Ipvsctl.Filename
 	if fileName == "" {
 		fileName = "/etc/ipvsmesh-ipvsctl.yaml"
@@ -160,7 +430,7 @@ func (s *IPVSApplierWorker) applyUpdate(target map[string]interface{}) error {
 		ipvsctlPath = "ipvsctl" // must be in system path if no specific path given
 	}
 
-	log.WithFields(log.Fields{
+	logIPVSApplier.WithFields(log.Fields{
 		"type": execType,
 		"file": fileName,
 		"cmd":  ipvsctlPath,
@@ -176,7 +446,7 @@ func (s *IPVSApplierWorker) applyUpdate(target map[string]interface{}) error {
 		if err != nil {
 			return err
 		}
-		ipvsctl := exec.Command(ipvsctlPath, "apply", "-f")
+		ipvsctl := exec.Command(ipvsctlPath, "apply", "-f", fileName)
 		return ipvsctl.Run()
 
 	case "exec-only":
@@ -195,38 +465,78 @@ func (s *IPVSApplierWorker) applyUpdate(target map[string]interface{}) error {
 	}
 }
 
+// apply applies target using ipvsctl, records the outcome and notifies publishers.
+// While paused, nothing is applied.
+func (s *IPVSApplierWorker) apply(target map[string]interface{}, serviceName string) {
+	s.mu.Lock()
+	if s.paused {
+		s.pending = true
+		s.mu.Unlock()
+		logIPVSApplier.WithField("service", serviceName).Debug("ipvsapplier: Paused, not applying update")
+		return
+	}
+	s.mu.Unlock()
+
+	start := time.Now()
+	err := s.applyUpdate(target)
+	s.recordApply(start, target, err)
+	if err != nil {
+		logIPVSApplier.WithField("err", err).Error("ipvsapplier: Unable to apply update")
+		emitEvent(EventApplyFailed, serviceName, err.Error(), map[string]string{"executionType": s.execType()})
+	} else {
+		emitEvent(EventApplySucceeded, serviceName, "Applied ipvsctl model", map[string]string{"executionType": s.execType()})
+	}
+
+	// Notify publishers about the change, so they can propagate it further
+	s.publisherUpdateChan <- PublisherUpdate{
+		data: target,
+	}
+}
+
 // Worker ...
 func (s *IPVSApplierWorker) Worker() {
-	log.Info("ipvsapplier: Starting IPVS applier...")
+	logIPVSApplier.Info("ipvsapplier: Starting IPVS applier...")
+	defer s.markExited()
 	for {
 		select {
 		case cfg := <-s.updateChan:
 			// if serviceName is empty, flush all services from local cache map but do not apply this (empty) config
 			if cfg.serviceName == "" {
+				s.mu.Lock()
 				s.services = make(map[string]IPVSApplierUpdateStruct, 5)
-				log.Debug("ipvsapplier: Flushing service cache after config refresh")
+				s.mu.Unlock()
+				logIPVSApplier.Debug("ipvsapplier: Flushing service cache after config refresh")
 				break
 			}
 
-			log.WithField("cfg", cfg).Debug("ipvsapplier: Received new ipvs update")
+			logIPVSApplier.WithFields(log.Fields{
+				"service":     cfg.serviceName,
+				"numBackends": len(cfg.data),
+			}).Debug("ipvsapplier: Received new ipvs update")
 
 			target, err := s.integrateUpdate(cfg)
 			if err != nil {
-				log.WithField("err", err).Error("ipvsapplier: Unable to integrate update")
+				logIPVSApplier.WithField("err", err).Error("ipvsapplier: Unable to integrate update")
 			}
 
-			err = s.applyUpdate(target)
-			if err != nil {
-				log.WithField("err", err).Error("ipvsapplier: Unable to apply update")
-			}
+			s.apply(target, cfg.serviceName)
 
-			// Notify publishers about the change, so they can propagate it further
-			s.publisherUpdateChan <- PublisherUpdate{
-				data: target,
+		case <-s.reapplyChan:
+			s.mu.Lock()
+			if s.cfg == nil {
+				// nothing received yet, nothing to apply
+				s.mu.Unlock()
+				break
 			}
+			target := s.buildModel()
+			s.mu.Unlock()
+
+			logIPVSApplier.Debug("ipvsapplier: Re-applying model after override change")
+			s.apply(target, "")
 
 		case wg := <-*s.StoppableByChan.StopChan:
-			log.Info("ipvsapplier: Stopping IPVS Applier")
+			logIPVSApplier.Info("ipvsapplier: Stopping IPVS Applier")
+			s.shutdown()
 
 			wg.Done()
 			return
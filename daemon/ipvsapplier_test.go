@@ -0,0 +1,119 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// applierUpdate returns a cached update of the ipvs applier for service
+// with the given backends
+func applierUpdate(service *model.Service, backends ...model.DownwardBackendServer) IPVSApplierUpdateStruct {
+	return IPVSApplierUpdateStruct{
+		serviceName: service.Name,
+		service:     service,
+		data:        backends,
+	}
+}
+
+func TestBuildModelUsesFieldsOfEachService(t *testing.T) {
+	updates := map[string]IPVSApplierUpdateStruct{
+		"api": applierUpdate(&model.Service{
+			Name:           "api",
+			Address:        "tcp://10.0.0.1:80",
+			Type:           "proxyFromFile",
+			ServiceOptions: model.ServiceOptions{SchedName: "rr"},
+		}, model.DownwardBackendServer{Address: "tcp://20.0.0.1:80", Weight: -1}),
+		"web": applierUpdate(&model.Service{
+			Name:           "web",
+			Address:        "tcp://10.0.0.2:80",
+			Type:           "dockerFrontProxy",
+			ServiceOptions: model.ServiceOptions{SchedName: "lc"},
+		}, model.DownwardBackendServer{Address: "tcp://20.0.0.2:80", Weight: -1}),
+	}
+
+	target := buildModel(resolveServices(updates, nil))
+	services := target["services"].([]interface{})
+	if len(services) != 2 {
+		t.Fatalf("got %d services, expected 2", len(services))
+	}
+
+	expected := []map[string]interface{}{
+		{"ipvsmesh.service.name": "api", "address": "tcp://10.0.0.1:80", "ipvsmesh.service.type": "proxyFromFile", "sched": "rr"},
+		{"ipvsmesh.service.name": "web", "address": "tcp://10.0.0.2:80", "ipvsmesh.service.type": "dockerFrontProxy", "sched": "lc"},
+	}
+	for idx, e := range expected {
+		ts := services[idx].(map[string]interface{})
+		for k, v := range e {
+			if ts[k] != v {
+				t.Errorf("service %d: got %s %v, expected %v", idx, k, ts[k], v)
+			}
+		}
+	}
+}
+
+func TestResolveServiceWeights(t *testing.T) {
+	service := &model.Service{
+		Name:           "web",
+		Address:        "tcp://10.0.0.1:80",
+		ServiceOptions: model.ServiceOptions{Weight: 500},
+	}
+
+	tests := []struct {
+		name     string
+		weights  []int // weights reported by the plugin, per backend
+		expected []int
+	}{
+		{"no dynamic weights", []int{-1, -1}, []int{500, 500}},
+		{"dynamic weights", []int{10, 20}, []int{10, 20}},
+		{"dynamic weight does not leak to next backend", []int{10, -1, -1}, []int{10, 500, 500}},
+		{"dynamic weight between others", []int{-1, 10, -1}, []int{500, 10, 500}},
+	}
+	for _, test := range tests {
+		backends := make([]model.DownwardBackendServer, len(test.weights))
+		for idx, w := range test.weights {
+			backends[idx] = model.DownwardBackendServer{Address: "tcp://20.0.0.1:80", Weight: w}
+		}
+
+		res := resolveService(applierUpdate(service, backends...), nil)
+		for idx, backend := range res.Backends {
+			if backend.Weight != test.expected[idx] {
+				t.Errorf("%s: backend %d: got weight %d, expected %d", test.name, idx, backend.Weight, test.expected[idx])
+			}
+		}
+	}
+}
+
+func TestResolveServiceSocketFrontProxyWeight(t *testing.T) {
+	service := &model.Service{
+		Name:    "sfp",
+		Address: "tcp://10.0.0.1:80",
+		Type:    "socketFrontProxy",
+		Globals: &model.Globals{
+			Defaults: model.Defaults{
+				Types: map[string]model.ServiceOptions{
+					"socketFrontProxy": {Weight: 200},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		weight   int // as reported by the plugin
+		expected int
+	}{
+		// socketFrontProxy used to report 0, which is a dynamic weight
+		// and takes the backend out of scheduling
+		{"weight 0 as reported before", 0, 0},
+		// it now reports -1 and gets the weight of its type defaults
+		{"weight -1 as reported now", -1, 200},
+	}
+	for _, test := range tests {
+		u := applierUpdate(service, model.DownwardBackendServer{Address: "tcp://172.17.0.2:80", Weight: test.weight})
+		res := resolveService(u, nil)
+		if res.Backends[0].Weight != test.expected {
+			t.Errorf("%s: got weight %d, expected %d", test.name, res.Backends[0].Weight, test.expected)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"github.com/aschmidt75/ipvsmesh/logging"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetLogLevel changes the log level of a single component or the global
+// log level at runtime. It returns the effective levels of all components.
+func (s *Service) SetLogLevel(ctx context.Context, req *localinterface.LogLevelRequest) (*localinterface.LogLevelResponse, error) {
+	if req.RevertAfterSecs < 0 {
+		return nil, status.Error(codes.InvalidArgument, "revertAfterSecs must not be negative")
+	}
+	if req.Level != "" {
+		level, err := log.ParseLevel(req.Level)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		err = logging.SetLevel(req.Component, level, time.Duration(req.RevertAfterSecs)*time.Second)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		log.WithFields(log.Fields{
+			"component":       req.Component,
+			"level":           level,
+			"revertAfterSecs": req.RevertAfterSecs,
+		}).Info("daemon: Changed log level")
+	}
+
+	res := &localinterface.LogLevelResponse{
+		Levels: make([]*localinterface.ComponentLogLevel, 0),
+	}
+	for _, cl := range logging.Levels() {
+		res.Levels = append(res.Levels, &localinterface.ComponentLogLevel{
+			Component: cl.Component,
+			Level:     cl.Level.String(),
+			Override:  cl.Override,
+		})
+	}
+	return res, nil
+}
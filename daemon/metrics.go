@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics of the processing pipeline, exposed on /metrics
+var (
+	metricConfigReads = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ipvsmesh",
+		Name:      "config_reads_total",
+		Help:      "Number of valid configurations read.",
+	})
+	metricConfigRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ipvsmesh",
+		Name:      "config_rejections_total",
+		Help:      "Number of configurations rejected because of errors.",
+	})
+
+	metricServiceBackends = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ipvsmesh",
+		Name:      "service_backends",
+		Help:      "Number of backends per service in the ipvs model.",
+	}, []string{"service"})
+	metricServiceWeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "ipvsmesh",
+		Name:      "service_weight_total",
+		Help:      "Sum of backend weights per service in the ipvs model.",
+	}, []string{"service"})
+
+	metricApplyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "ipvsmesh",
+		Name:      "apply_duration_seconds",
+		Help:      "Duration of applying the ipvs model, per execution type.",
+	}, []string{"execution_type"})
+	metricApplyFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ipvsmesh",
+		Name:      "apply_failures_total",
+		Help:      "Number of failed applies of the ipvs model, per execution type.",
+	}, []string{"execution_type"})
+
+	metricPluginNotifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ipvsmesh",
+		Name:      "plugin_notifications_total",
+		Help:      "Number of update notifications from plugins, per plugin type.",
+	}, []string{"plugin"})
+	metricPluginErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ipvsmesh",
+		Name:      "plugin_errors_total",
+		Help:      "Number of errors querying backends from plugins, per plugin type.",
+	}, []string{"plugin"})
+
+	metricPublishes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ipvsmesh",
+		Name:      "publishes_total",
+		Help:      "Number of pushes to publishers, per publisher type and result.",
+	}, []string{"publisher", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricConfigReads,
+		metricConfigRejections,
+		metricServiceBackends,
+		metricServiceWeight,
+		metricApplyDuration,
+		metricApplyFailures,
+		metricPluginNotifications,
+		metricPluginErrors,
+		metricPublishes,
+	)
+}
+
+// updateServiceMetrics sets backend gauges from resolved services. Services
+// not present any more are dropped. Removed backends are not counted.
+func updateServiceMetrics(services []ServiceBackends) {
+	metricServiceBackends.Reset()
+	metricServiceWeight.Reset()
+
+	now := time.Now()
+	for _, service := range services {
+		num, weight := 0, 0
+		for _, backend := range service.Backends {
+			if backend.Removed(now) {
+				continue
+			}
+			num++
+			weight += backend.Weight
+		}
+		metricServiceBackends.WithLabelValues(service.Name).Set(float64(num))
+		metricServiceWeight.WithLabelValues(service.Name).Set(float64(weight))
+	}
+}
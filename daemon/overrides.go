@@ -0,0 +1,132 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// BackendOverride is a runtime weight override for a single backend,
+// installed via the local api. It survives plugin updates until cleared.
+type BackendOverride struct {
+	Service string
+	Address string
+	Weight  int
+	Since   time.Time
+
+	// RemoveAt is the time after which the backend is removed
+	// from the model. Zero means it is only reweighted.
+	RemoveAt time.Time
+}
+
+// Removed returns true if the backend has been removed by this override
+func (o BackendOverride) Removed(now time.Time) bool {
+	return !o.RemoveAt.IsZero() && !now.Before(o.RemoveAt)
+}
+
+// hasBackend returns true if the service is known and has a backend
+// with given address. Caller must hold s.mu
+func (s *IPVSApplierWorker) hasBackend(serviceName, address string) (bool, error) {
+	u, ex := s.services[serviceName]
+	if !ex {
+		return false, fmt.Errorf("no such service: %s", serviceName)
+	}
+	for _, d := range u.data {
+		if d.Address == address {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("no such backend %s in service %s", address, serviceName)
+}
+
+// DrainBackend sets the weight of a backend to 0 until it is enabled again.
+// If removeAfter is > 0, the backend is removed from the model after that period.
+func (s *IPVSApplierWorker) DrainBackend(serviceName, address string, removeAfter time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.hasBackend(serviceName, address); err != nil {
+		return err
+	}
+
+	o := BackendOverride{
+		Service: serviceName,
+		Address: address,
+		Weight:  0,
+		Since:   time.Now(),
+	}
+	if removeAfter > 0 {
+		o.RemoveAt = o.Since.Add(removeAfter)
+		time.AfterFunc(removeAfter, s.triggerReapply)
+	}
+
+	if s.overrides[serviceName] == nil {
+		s.overrides[serviceName] = make(map[string]BackendOverride)
+	}
+	s.overrides[serviceName][address] = o
+
+	fields := map[string]string{"address": address}
+	if removeAfter > 0 {
+		fields["removeAfterSecs"] = strconv.Itoa(int(removeAfter / time.Second))
+	}
+	emitEvent(EventBackendDrained, serviceName, "Backend drained", fields)
+	logIPVSApplier.WithField("override", o).Info("ipvsapplier: Draining backend")
+
+	s.triggerReapply()
+	return nil
+}
+
+// EnableBackend clears a runtime override of a backend
+func (s *IPVSApplierWorker) EnableBackend(serviceName, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ex := s.overrides[serviceName][address]; !ex {
+		return fmt.Errorf("no override for backend %s in service %s", address, serviceName)
+	}
+	delete(s.overrides[serviceName], address)
+	if len(s.overrides[serviceName]) == 0 {
+		delete(s.overrides, serviceName)
+	}
+
+	emitEvent(EventBackendEnabled, serviceName, "Backend enabled", map[string]string{"address": address})
+	logIPVSApplier.WithFields(log.Fields{
+		"service": serviceName,
+		"address": address,
+	}).Info("ipvsapplier: Enabled backend")
+
+	s.triggerReapply()
+	return nil
+}
+
+// Overrides returns all active runtime overrides, sorted by service and address
+func (s *IPVSApplierWorker) Overrides() []BackendOverride {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]BackendOverride, 0)
+	for _, m := range s.overrides {
+		for _, o := range m {
+			res = append(res, o)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Service != res[j].Service {
+			return res[i].Service < res[j].Service
+		}
+		return res[i].Address < res[j].Address
+	})
+	return res
+}
+
+// triggerReapply makes the worker rebuild and apply the model. It does not block.
+func (s *IPVSApplierWorker) triggerReapply() {
+	select {
+	case s.reapplyChan <- struct{}{}:
+	default:
+		// already pending
+	}
+}
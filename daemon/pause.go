@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Pause stops applying updates to IPVS. Updates from plugins are still
+// integrated into the model, but neither ipvsctl nor publishers are
+// called until Resume.
+func (s *IPVSApplierWorker) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused {
+		return
+	}
+	s.paused = true
+	s.pausedSince = time.Now()
+	s.pending = false
+
+	logIPVSApplier.Info("ipvsapplier: Paused applying updates")
+	emitEvent(EventApplyPaused, "", "Paused applying updates", nil)
+}
+
+// Resume continues applying updates. The latest model is applied once.
+func (s *IPVSApplierWorker) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.paused {
+		return
+	}
+	s.paused = false
+
+	logIPVSApplier.WithField("pending", s.pending).Info("ipvsapplier: Resumed applying updates")
+	emitEvent(EventApplyResumed, "", "Resumed applying updates", nil)
+
+	s.triggerReapply()
+}
+
+// Paused returns true if applying is paused, since when, and
+// whether updates have been held back since then.
+func (s *IPVSApplierWorker) Paused() (bool, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.paused, s.pausedSince, s.pending
+}
+
+// Pause puts the daemon in maintenance mode, it stops applying to IPVS
+func (s *Service) Pause(context.Context, *localinterface.Empty) (*localinterface.Empty, error) {
+	if s.IPVSApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no ipvs applier active")
+	}
+	s.IPVSApplier.Pause()
+	return &localinterface.Empty{}, nil
+}
+
+// Resume ends maintenance mode and applies the latest model
+func (s *Service) Resume(context.Context, *localinterface.Empty) (*localinterface.Empty, error) {
+	if s.IPVSApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no ipvs applier active")
+	}
+	s.IPVSApplier.Resume()
+	return &localinterface.Empty{}, nil
+}
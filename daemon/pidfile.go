@@ -0,0 +1,247 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AlreadyRunningError is returned if another daemon holds the pidfile
+// or serves the socket.
+type AlreadyRunningError struct {
+	PID     int // 0 if unknown
+	PidFile string
+	Socket  string
+}
+
+func (e *AlreadyRunningError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("another ipvsmesh daemon is serving socket %s", e.Socket)
+	}
+	return fmt.Sprintf("another ipvsmesh daemon is already running with PID %d (pidfile %s, socket %s)", e.PID, e.PidFile, e.Socket)
+}
+
+// PidFile is an exclusively locked file containing the PID of the
+// running daemon. The lock is released when the process exits.
+type PidFile struct {
+	path string
+	f    *os.File
+}
+
+// runDir keeps the pidfile of a daemon running as root
+const runDir = "/run"
+
+// DefaultPidFile derives the name of the pidfile from the socket path. When
+// running as root, it is placed in /run instead of next to the socket, as
+// the socket directory, e.g. /tmp, may be writable by other users.
+func DefaultPidFile(socketPath string) string {
+	name := strings.TrimSuffix(socketPath, ".sock") + ".pid"
+	if os.Geteuid() == 0 {
+		return filepath.Join(runDir, filepath.Base(name))
+	}
+	return name
+}
+
+// AcquirePidFile locks the pidfile and writes the current PID to it. If another
+// process holds the lock, an AlreadyRunningError with its PID is returned.
+// Symbolic links are not followed, and the pidfile must be a regular file.
+func AcquirePidFile(path, socketPath string) (*PidFile, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|syscall.O_NOFOLLOW, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			f.Close()
+			if err == syscall.EWOULDBLOCK {
+				return nil, &AlreadyRunningError{PID: readPid(path), PidFile: path, Socket: socketPath}
+			}
+			return nil, err
+		}
+
+		// a previous daemon may have removed the file after we opened it,
+		// the lock is only valid if the path still refers to it.
+		current, err := lockedFileCurrent(f, path)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if !current {
+			f.Close()
+			continue
+		}
+
+		return writePid(f, path)
+	}
+}
+
+// lockedFileCurrent checks that f is a regular file with a single link,
+// and that path still refers to it.
+func lockedFileCurrent(f *os.File, path string) (bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !info.Mode().IsRegular() || !ok || st.Nlink > 1 {
+		return false, fmt.Errorf("refusing pidfile %s: not a regular file", path)
+	}
+
+	pathInfo, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(info, pathInfo), nil
+}
+
+// writePid replaces the content of the locked pidfile f by the current PID
+func writePid(f *os.File, path string) (*PidFile, error) {
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.WriteAt([]byte(fmt.Sprintf("%d\n", os.Getpid())), 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &PidFile{path: path, f: f}, nil
+}
+
+// Release removes the pidfile and releases the lock. The file is removed
+// while still locked, a new daemon waiting for the lock notices that and
+// creates a new one.
+func (p *PidFile) Release() {
+	os.Remove(p.path)
+	p.f.Close()
+}
+
+// CheckPidFile returns an AlreadyRunningError if the pidfile is locked by another process
+func CheckPidFile(path, socketPath string) error {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		// no pidfile, or unable to check. Acquiring will fail later in that case.
+		return nil
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == syscall.EWOULDBLOCK {
+		return &AlreadyRunningError{PID: readPid(path), PidFile: path, Socket: socketPath}
+	}
+	return nil
+}
+
+// readPid returns the PID stored in a pidfile, or 0
+func readPid(path string) int {
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// socketProbeTimeout bounds the status request to a daemon which may be
+// serving an existing socket
+const socketProbeTimeout = 2 * time.Second
+
+// RemoveStaleSocket checks if the socket file exists and is served by another
+// daemon, by sending it a status request. If so, an AlreadyRunningError is
+// returned. If nobody is listening on it, e.g. after a crash, the stale socket
+// file is removed and true is returned. A socket which accepts connections but
+// fails the status request is not removed, an error is returned instead.
+// It must only be called while holding the pidfile, so that concurrently
+// starting daemons do not remove each other's socket. The other daemon uses
+// a different pidfile then, so its PID is unknown.
+func RemoveStaleSocket(socketPath string) (bool, error) {
+	return removeStaleSocket(socketPath, socketProbeTimeout)
+}
+
+func removeStaleSocket(socketPath string, timeout time.Duration) (bool, error) {
+	if _, err := os.Stat(socketPath); err != nil {
+		return false, nil
+	}
+
+	refused, err := probeSocket(socketPath, timeout)
+	if err == nil {
+		return false, &AlreadyRunningError{Socket: socketPath}
+	}
+	if !refused {
+		return false, fmt.Errorf("socket %s accepts connections but the status request failed, not removing it: %s", socketPath, err)
+	}
+
+	if err := os.Remove(socketPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// probeSocket sends a status request via socketPath. An answer of a grpc
+// server, even an error, means that the socket is served. Otherwise the
+// error is returned, and refused is true if nobody is listening on the socket.
+func probeSocket(socketPath string, timeout time.Duration) (refused bool, err error) {
+	var mu sync.Mutex
+	var dialErr error
+
+	conn, err := grpc.Dial(socketPath, grpc.WithInsecure(), grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+		c, err := net.DialTimeout("unix", addr, timeout)
+		mu.Lock()
+		dialErr = err
+		mu.Unlock()
+		return c, err
+	}))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, err = localinterface.NewDaemonServiceClient(conn).Status(ctx, &localinterface.Empty{})
+	if err == nil {
+		return false, nil
+	}
+	if code := status.Code(err); code != codes.Unavailable && code != codes.DeadlineExceeded {
+		// answered by a grpc server
+		return false, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return isConnectionRefused(dialErr), err
+}
+
+// isConnectionRefused returns true if err is a refused connection
+func isConnectionRefused(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+			return sysErr.Err == syscall.ECONNREFUSED
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestAcquirePidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipvsmesh-pidfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ipvsmesh.pid")
+
+	pf, err := AcquirePidFile(path, "test.sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid := readPid(path); pid != os.Getpid() {
+		t.Errorf("got PID %d, expected %d", pid, os.Getpid())
+	}
+
+	_, err = AcquirePidFile(path, "test.sock")
+	if e, ok := err.(*AlreadyRunningError); !ok || e.PID != os.Getpid() {
+		t.Errorf("expected AlreadyRunningError with PID %d, got %v", os.Getpid(), err)
+	}
+	if err := CheckPidFile(path, "test.sock"); err == nil {
+		t.Error("expected CheckPidFile to report the running daemon")
+	}
+
+	pf.Release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("pidfile has not been removed: %v", err)
+	}
+	pf, err = AcquirePidFile(path, "test.sock")
+	if err != nil {
+		t.Fatalf("unable to acquire released pidfile: %s", err)
+	}
+	pf.Release()
+}
+
+func TestAcquirePidFileRefusesLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipvsmesh-pidfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "target")
+	if err := ioutil.WriteFile(target, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	symlink := filepath.Join(dir, "symlink.pid")
+	if err := os.Symlink(target, symlink); err != nil {
+		t.Fatal(err)
+	}
+	hardlink := filepath.Join(dir, "hardlink.pid")
+	if err := os.Link(target, hardlink); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{symlink, hardlink} {
+		if pf, err := AcquirePidFile(path, "test.sock"); err == nil {
+			pf.Release()
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+	if b, _ := ioutil.ReadFile(target); string(b) != "keep" {
+		t.Errorf("link target has been modified: %q", b)
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipvsmesh-socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	listen := func(name string) *net.UnixListener {
+		l, err := net.ListenUnix("unix", &net.UnixAddr{Name: filepath.Join(dir, name), Net: "unix"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	// a socket file nobody listens on, left over by a crashed daemon
+	stale := listen("stale.sock")
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	// a grpc server, answering with an error
+	served := listen("served.sock")
+	server := grpc.NewServer()
+	go server.Serve(served)
+	defer server.Stop()
+
+	// a process accepting connections, without answering
+	silent := listen("silent.sock")
+	defer silent.Close()
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		socket  string
+		removed bool
+		running bool // AlreadyRunningError expected
+		err     bool // other error expected
+	}{
+		{"missing", "missing.sock", false, false, false},
+		{"stale", "stale.sock", true, false, false},
+		{"served", "served.sock", false, true, false},
+		{"not answering", "silent.sock", false, false, true},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.socket)
+		removed, err := removeStaleSocket(path, 500*time.Millisecond)
+		if removed != test.removed {
+			t.Errorf("%s: got removed %v, expected %v", test.name, removed, test.removed)
+		}
+		_, running := err.(*AlreadyRunningError)
+		if running != test.running || (err != nil && !running) != test.err {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+		if _, statErr := os.Stat(path); test.socket != "missing.sock" && os.IsNotExist(statErr) != test.removed {
+			t.Errorf("%s: socket file exists: %v", test.name, !os.IsNotExist(statErr))
+		}
+	}
+}
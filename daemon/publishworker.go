@@ -1,12 +1,16 @@
 package daemon
 
 import (
+	"fmt"
 	"sync"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	log "github.com/sirupsen/logrus"
 )
 
+var logPublisher = logging.Component("publisher")
+
 // PublisherUpdate is a message from the ipvs applier indicating
 // that an endpoint has changed. It is the raw data structure for
 // the ipvsctl model
@@ -32,9 +36,12 @@ type PublisherhWorker struct {
 
 	// maps publisher names to their specs
 	publisherSpecs map[string]*model.Publisher
+	mu             sync.Mutex
 
-	// remember what services (by name) we have published.
-	publishedServices map[string]bool
+	// remember what services we have published, by service name
+	// and publisher name. Values are the published service entry
+	// of the ipvsctl model, see publishedVersion.
+	publishedServices map[string]map[string]string
 
 	onceFlag bool
 	onceCh   chan struct{}
@@ -46,6 +53,7 @@ func NewPublisherWorker(updateChan PublisherUpdateChanType, configUpdateCh Publi
 	return &PublisherhWorker{
 		StoppableByChan: StoppableByChan{
 			StopChan: &sc,
+			Name:     "publisher",
 		},
 		updateCh:       updateChan,
 		configUpdateCh: configUpdateCh,
@@ -53,8 +61,20 @@ func NewPublisherWorker(updateChan PublisherUpdateChanType, configUpdateCh Publi
 		onceCh:         onceCh,
 
 		publisherSpecs:    make(map[string]*model.Publisher, 5),
-		publishedServices: make(map[string]bool, 5),
+		publishedServices: make(map[string]map[string]string, 5),
+	}
+}
+
+// Publishers returns a list of all currently registered publishers
+func (s *PublisherhWorker) Publishers() []*model.Publisher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]*model.Publisher, 0, len(s.publisherSpecs))
+	for _, publisher := range s.publisherSpecs {
+		res = append(res, publisher)
 	}
+	return res
 }
 
 func (s *PublisherhWorker) getLabelsByServiceName(name string) (map[string]string, bool) {
@@ -83,7 +103,7 @@ func (s *PublisherhWorker) getFirstServiceByLabels(matchLabels map[string]string
 }
 func (s *PublisherhWorker) findPublishersByLabels(matchLabels map[string]string) []*model.Publisher {
 	res := make([]*model.Publisher, 0, 5)
-	log.WithField("matchLabels", matchLabels).Trace("Looking for publishers with these labels")
+	logPublisher.WithField("matchLabels", matchLabels).Trace("Looking for publishers with these labels")
 
 	for publisherName, publisher := range s.publisherSpecs {
 		/*		log.WithFields(log.Fields{
@@ -100,7 +120,7 @@ func (s *PublisherhWorker) findPublishersByLabels(matchLabels map[string]string)
 		}
 
 		if found {
-			log.WithFields(log.Fields{
+			logPublisher.WithFields(log.Fields{
 				"name": publisherName,
 				"l":    matchLabels,
 			}).Trace("Found publisher by labels")
@@ -117,7 +137,7 @@ func (s *PublisherhWorker) walkUpdate_DELETE(upd PublisherUpdate) ([]*model.Publ
 	servicesRaw, ex := upd.data["services"]
 	if !ex {
 		// no services there. Notify all publishers to take down existings endpoints
-		log.Debug("PublisherWorker: No services")
+		logPublisher.Debug("PublisherWorker: No services")
 		return make([]*model.Publisher, 0), nil
 	}
 
@@ -131,21 +151,21 @@ func (s *PublisherhWorker) walkUpdate_DELETE(upd PublisherUpdate) ([]*model.Publ
 		// find out with ipvsmesh service is behind this ipvsctl service
 		serviceName, ex := service["ipvsmesh.service.name"].(string)
 		if !ex {
-			log.WithField("serviceName", serviceName).Error("PublisherWorker: Internal error, unable to track ipvsctl service.")
+			logPublisher.WithField("serviceName", serviceName).Error("PublisherWorker: Internal error, unable to track ipvsctl service.")
 			continue
 		}
 
 		// look up labels of service
 		labels, found := s.getLabelsByServiceName(serviceName)
 		if !found {
-			log.WithField("serviceName", serviceName).Error("PublisherWorker: Internal error, unable to find ipvsctl service (2).")
+			logPublisher.WithField("serviceName", serviceName).Error("PublisherWorker: Internal error, unable to find ipvsctl service (2).")
 			continue
 		}
-		log.WithField("labels", labels).Trace("PublisherWorker: Labels for service name")
+		logPublisher.WithField("labels", labels).Trace("PublisherWorker: Labels for service name")
 
 		// look up publishers with these labels
 		publishers := s.findPublishersByLabels(labels)
-		log.WithFields(log.Fields{
+		logPublisher.WithFields(log.Fields{
 			"num":         len(publishers),
 			"serviceName": serviceName,
 		}).Trace("PublisherWorker: Found publishers for matchLabels")
@@ -164,24 +184,130 @@ func (s *PublisherhWorker) walkUpdate_DELETE(upd PublisherUpdate) ([]*model.Publ
 	return res, nil
 }
 
+// publish pushes all services of upd to the publishers watching them, unless
+// the same version of a service has been pushed to a publisher successfully
+// before. Failed pushes are retried with the next update. Services missing
+// from upd are forgotten, so they are published again when they reappear.
+// TODO: Remove endpoints
+func (s *PublisherhWorker) publish(upd PublisherUpdate) {
+	services, _ := upd.data["services"].([]interface{})
+
+	current := make(map[string]bool, len(services))
+	for _, serviceRaw := range services {
+		service, ok := serviceRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		serviceName, _ := service["ipvsmesh.service.name"].(string)
+		address, _ := service["address"].(string)
+		current[serviceName] = true
+
+		origin := s.getServiceByName(serviceName)
+		if origin == nil {
+			logPublisher.WithField("serviceName", serviceName).Error("PublisherWorker: Internal error, unable to find ipvsctl service.")
+			continue
+		}
+
+		version := publishedVersion(service)
+		published, ex := s.publishedServices[serviceName]
+		if !ex {
+			published = make(map[string]string)
+			s.publishedServices[serviceName] = published
+		}
+
+		for _, publisher := range s.Publishers() {
+			if publisher.Plugin == nil || !publisher.Plugin.HasUpwardInterface() || !labelsMatch(publisher.MatchLabels, origin.Labels) {
+				continue
+			}
+			if published[publisher.Name] == version {
+				continue
+			}
+
+			err := publisher.Plugin.PushUpwardData(model.UpwardData{
+				Address:         address,
+				ServiceName:     serviceName,
+				OriginService:   origin,
+				TargetPublisher: publisher,
+			})
+			fields := map[string]string{
+				"publisher": publisher.Name,
+				"address":   address,
+			}
+			if err != nil {
+				logPublisher.WithFields(log.Fields{
+					"err":       err,
+					"publisher": publisher.Name,
+					"service":   serviceName,
+				}).Error("PublisherWorker: Unable to push update")
+				emitEvent(EventPublishFailed, serviceName, err.Error(), fields)
+				metricPublishes.WithLabelValues(publisher.Type, "failure").Inc()
+
+				// retry with the next update
+				delete(published, publisher.Name)
+				continue
+			}
+			emitEvent(EventPublishSucceeded, serviceName, "Published service", fields)
+			metricPublishes.WithLabelValues(publisher.Type, "success").Inc()
+			published[publisher.Name] = version
+		}
+	}
+
+	for serviceName := range s.publishedServices {
+		if !current[serviceName] {
+			delete(s.publishedServices, serviceName)
+		}
+	}
+}
+
+// publishedVersion identifies a service entry of the ipvsctl model, including
+// its destinations, so that changed services are published again.
+func publishedVersion(service map[string]interface{}) string {
+	// maps are printed with sorted keys
+	return fmt.Sprintf("%v", service)
+}
+
+func (s *PublisherhWorker) getServiceByName(name string) *model.Service {
+	for _, service := range s.cfg.Services {
+		if service.Name == name {
+			return service
+		}
+	}
+	return nil
+}
+
+// labelsMatch returns true if all entries of a non-empty selector are part of labels
+func labelsMatch(selector map[string]string, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		vv, ex := labels[k]
+		if !ex || v != vv {
+			return false
+		}
+	}
+	return true
+}
+
 // Worker checks downward notifications
 func (s *PublisherhWorker) Worker() {
-	log.Info("Starting publish worker...")
+	logPublisher.Info("Starting publish worker...")
+	defer s.markExited()
 
 	for {
 		select {
 		case upd := <-s.updateCh:
-			log.WithField("upd", upd).Debug("PublisherWorker: got backend update for publishing")
+			logPublisher.WithField("upd", upd).Debug("PublisherWorker: got backend update for publishing")
 			/*
 				publishers, err := s.walkUpdate(upd)
 				if err != nil {
-					log.WithField("err", err).Error("PublisherWorker: Unable to process publisher update")
+					logPublisher.WithField("err", err).Error("PublisherWorker: Unable to process publisher update")
 					continue
 				}
 
 				err = s.triggerPublishing(publishers)
 				if err != nil {
-					log.WithField("err", err).Error("PublisherWorker: Unable to publish update")
+					logPublisher.WithField("err", err).Error("PublisherWorker: Unable to publish update")
 					continue
 				}
 			*/
@@ -193,32 +319,34 @@ func (s *PublisherhWorker) Worker() {
 			// 1. "new" contains a service and "previous" does not. Publish new endpoint
 			// 2. "new" contains a service and "previous" does also. Do nothing.
 			// 3. "new" is missing a service which "previous" contained. Remove endpoint
+			s.publish(upd)
 
 			// "new" is recent now
 
 			if s.onceFlag {
-				log.Info("PublisherWorker: Stopping due to --once")
+				logPublisher.Info("PublisherWorker: Stopping due to --once")
 				s.onceCh <- struct{}{}
 			}
 
 		case cfg := <-s.configUpdateCh:
-			log.WithField("numPublishers", len(cfg.Publishers)).Debug("PublisherWorker: got config update")
+			logPublisher.WithField("numPublishers", len(cfg.Publishers)).Debug("PublisherWorker: got config update")
 
 			s.cfg = cfg
 
+			s.mu.Lock()
 			for _, publisher := range cfg.Publishers {
 
 				_, ex := s.publisherSpecs[publisher.Name]
 
 				if !ex {
-					log.WithFields(log.Fields{
-						"spec": publisher.Spec,
+					logPublisher.WithFields(log.Fields{
+						"type": publisher.Type,
 						"name": publisher.Name,
 					}).Trace("New publisher")
 					s.publisherSpecs[publisher.Name] = publisher
 				} else {
-					log.WithFields(log.Fields{
-						"spec": publisher.Spec,
+					logPublisher.WithFields(log.Fields{
+						"type": publisher.Type,
 						"name": publisher.Name,
 					}).Trace("Updated publisher")
 					s.publisherSpecs[publisher.Name] = publisher
@@ -243,12 +371,13 @@ func (s *PublisherhWorker) Worker() {
 					// it will not be valid any more.
 
 					delete(s.publisherSpecs, k)
-					log.WithField("name", k).Trace("Deleted publisher")
+					logPublisher.WithField("name", k).Trace("Deleted publisher")
 				}
 			}
+			s.mu.Unlock()
 
 		case wg := <-*s.StoppableByChan.StopChan:
-			log.Info("Stopping publish worker")
+			logPublisher.Info("Stopping publish worker")
 			wg.Done()
 			return
 		}
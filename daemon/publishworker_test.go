@@ -0,0 +1,119 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// countingPublisher is a publisher plugin which counts pushes, and fails
+// while err is set
+type countingPublisher struct {
+	pushes int
+	err    error
+}
+
+func (p *countingPublisher) Name() string                            { return "counting" }
+func (p *countingPublisher) Validate() error                         { return nil }
+func (p *countingPublisher) Initialize(globals *model.Globals) error { return nil }
+func (p *countingPublisher) HasDownwardInterface() bool              { return false }
+func (p *countingPublisher) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}) error {
+	return nil
+}
+func (p *countingPublisher) GetDownwardData() ([]model.DownwardBackendServer, error) {
+	return nil, nil
+}
+func (p *countingPublisher) HasUpwardInterface() bool { return true }
+func (p *countingPublisher) PushUpwardData(data model.UpwardData) error {
+	p.pushes++
+	return p.err
+}
+
+func publisherUpdate(destinations ...string) PublisherUpdate {
+	tds := make([]interface{}, len(destinations))
+	for idx, d := range destinations {
+		tds[idx] = map[string]interface{}{"address": d, "weight": 1000}
+	}
+	return PublisherUpdate{data: map[string]interface{}{
+		"services": []interface{}{
+			map[string]interface{}{
+				"address":               "10.0.0.1:80",
+				"ipvsmesh.service.name": "web",
+				"destinations":          tds,
+			},
+		},
+	}}
+}
+
+func TestPublish(t *testing.T) {
+	plugin := &countingPublisher{}
+	s := NewPublisherWorker(nil, nil, false, nil)
+	s.cfg.Services = []*model.Service{
+		{Name: "web", Labels: map[string]string{"svc": "a"}},
+	}
+	s.publisherSpecs["pub"] = &model.Publisher{
+		Name:        "pub",
+		MatchLabels: map[string]string{"svc": "a"},
+		Plugin:      plugin,
+	}
+
+	steps := []struct {
+		name   string
+		upd    PublisherUpdate
+		err    error
+		pushes int // total number of pushes after this step
+	}{
+		{"new service", publisherUpdate("20.0.0.1:80"), nil, 1},
+		{"unchanged service", publisherUpdate("20.0.0.1:80"), nil, 1},
+		{"changed backends", publisherUpdate("20.0.0.1:80", "20.0.0.2:80"), nil, 2},
+		{"failed push", publisherUpdate("20.0.0.1:80"), errors.New("unavailable"), 3},
+		{"retry after failure", publisherUpdate("20.0.0.1:80"), nil, 4},
+		{"unchanged after retry", publisherUpdate("20.0.0.1:80"), nil, 4},
+		{"service removed", PublisherUpdate{data: map[string]interface{}{}}, nil, 4},
+		{"service reappears", publisherUpdate("20.0.0.1:80"), nil, 5},
+	}
+	for _, step := range steps {
+		plugin.err = step.err
+		s.publish(step.upd)
+		if plugin.pushes != step.pushes {
+			t.Errorf("%s: got %d pushes, expected %d", step.name, plugin.pushes, step.pushes)
+		}
+	}
+}
+
+func TestLabelsMatch(t *testing.T) {
+	labels := map[string]string{"svc": "a", "tier": "web"}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		expected bool
+	}{
+		{"empty selector", map[string]string{}, false},
+		{"nil selector", nil, false},
+		{"single match", map[string]string{"svc": "a"}, true},
+		{"full match", map[string]string{"svc": "a", "tier": "web"}, true},
+		{"value differs", map[string]string{"svc": "b"}, false},
+		{"key missing", map[string]string{"zone": "x"}, false},
+		{"partial match", map[string]string{"svc": "a", "zone": "x"}, false},
+	}
+	for _, test := range tests {
+		if res := labelsMatch(test.selector, labels); res != test.expected {
+			t.Errorf("%s: got %v, expected %v", test.name, res, test.expected)
+		}
+	}
+}
+
+func TestPublishedVersion(t *testing.T) {
+	a := publisherUpdate("20.0.0.1:80", "20.0.0.2:80").data["services"].([]interface{})[0].(map[string]interface{})
+	b := publisherUpdate("20.0.0.1:80", "20.0.0.2:80").data["services"].([]interface{})[0].(map[string]interface{})
+	c := publisherUpdate("20.0.0.1:80").data["services"].([]interface{})[0].(map[string]interface{})
+
+	if publishedVersion(a) != publishedVersion(b) {
+		t.Error("expected equal services to have the same version")
+	}
+	if publishedVersion(a) == publishedVersion(c) {
+		t.Error("expected services with different destinations to have different versions")
+	}
+}
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/config"
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reload forces the daemon to re-read its configuration file and
+// to re-query all service plugins. Configuration errors are returned
+// to the caller.
+func (s *Service) Reload(ctx context.Context, in *localinterface.Empty) (*localinterface.ReloadResponse, error) {
+	if s.ConfigWatcher == nil {
+		return nil, errors.New("no config watcher active")
+	}
+
+	res := &localinterface.ReloadResponse{
+		Applied: true,
+		Errors:  make([]string, 0),
+	}
+
+	err := s.ConfigWatcher.Reload(ctx)
+	if err != nil {
+		if err == context.Canceled || err == context.DeadlineExceeded {
+			return nil, err
+		}
+
+		res.Applied = false
+		res.Errors = errorStrings(err)
+	}
+	log.WithField("res", res).Debug("daemon: Reloaded configuration")
+
+	return res, nil
+}
+
+// reloadOnSignal triggers a reload, i.e. when receiving SIGHUP
+func (s *Service) reloadOnSignal() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Config().DefaultTimeout)*time.Second)
+	defer cancel()
+
+	res, err := s.Reload(ctx, &localinterface.Empty{})
+	if err != nil {
+		log.WithField("err", err).Error("daemon: Unable to reload configuration")
+		return
+	}
+	if !res.Applied {
+		log.WithField("errors", res.Errors).Warn("daemon: Configuration has errors, not applied")
+	}
+}
+
+// errorStrings returns the messages of all errors contained in err
+func errorStrings(err error) []string {
+	if errs, ok := err.(config.Errors); ok {
+		res := make([]string, len(errs))
+		for idx, e := range errs {
+			res[idx] = e.Error()
+		}
+		return res
+	}
+	return []string{err.Error()}
+}
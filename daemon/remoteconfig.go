@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// watchRemote polls the remote configuration source until stopped
+func (s *ConfigWatcherWorker) watchRemote() {
+	logConfigWatcher.WithField("url", s.remote.URL).Debug("configwatcher: Initial remote config read")
+	s.fetchRemote(false)
+
+	if s.onceFlag {
+		logConfigWatcher.Info("configwatcher: Stopping due to --once")
+		return
+	}
+
+	pollTicker := time.NewTicker(s.pollInterval)
+	defer pollTicker.Stop()
+
+	localNetworkTicker := time.NewTicker(localNetworkCheckInterval)
+	defer localNetworkTicker.Stop()
+
+	logConfigWatcher.WithField("interval", s.pollInterval).Debug("configwatcher: Polling remote config.")
+	for {
+		select {
+		case <-pollTicker.C:
+			s.fetchRemote(false)
+		case <-localNetworkTicker.C:
+			if s.remoteConfig != nil && s.localNetworkChanged() {
+				logConfigWatcher.Info("configwatcher: Local interface addresses changed, re-reading remote config")
+				s.readConfig()
+			}
+		case resCh := <-s.reloadChan:
+			logConfigWatcher.Info("configwatcher: Forced reload of remote config")
+			resCh <- s.fetchRemote(true)
+		case wg := <-*s.StoppableByChan.StopChan:
+			logConfigWatcher.Info("configwatcher: Stopping Configuratiom Watcher")
+			wg.Done()
+			return
+		}
+	}
+}
+
+// fetchRemote fetches the remote configuration and applies it if it has
+// changed. Valid configurations are written to the cache file. If the
+// source cannot be fetched before a valid configuration has been read, the
+// cached copy is applied instead.
+func (s *ConfigWatcherWorker) fetchRemote(force bool) error {
+	b, mt, err := s.remote.Fetch(force)
+	fromCache := false
+	if err != nil {
+		logConfigWatcher.WithFields(log.Fields{
+			"url": s.remote.URL,
+			"err": err,
+		}).Warn("configwatcher: Unable to fetch remote config")
+		if s.Loaded() {
+			// keep the current configuration
+			return err
+		}
+
+		var cacheErr error
+		b, mt, cacheErr = s.remote.ReadCache()
+		if cacheErr != nil {
+			logConfigWatcher.WithField("err", cacheErr).Error("configwatcher: No cached copy of remote config available")
+			emitEvent(EventConfigRejected, "", err.Error(), map[string]string{"file": s.configFileName})
+			metricConfigRejections.Inc()
+			return err
+		}
+		logConfigWatcher.WithField("cache", s.remote.CacheFile).Warn("configwatcher: Using cached copy of remote config")
+		fromCache = true
+	}
+	if b == nil {
+		logConfigWatcher.Trace("configwatcher: Remote config not modified")
+		return nil
+	}
+
+	s.remoteConfig = b
+	err = s.readConfig()
+	s.setLastModTime(mt)
+	if err != nil || fromCache {
+		return err
+	}
+
+	if err := s.remote.WriteCache(b); err != nil {
+		logConfigWatcher.WithFields(log.Fields{
+			"cache": s.remote.CacheFile,
+			"err":   err,
+		}).Warn("configwatcher: Unable to write cached copy of remote config")
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/config"
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backendState describes a backend as active, drained or removed
+func backendState(b Backend, now time.Time) string {
+	if b.Override == nil {
+		return "active"
+	}
+	if b.Override.Removed(now) {
+		return "removed"
+	}
+	return "drained"
+}
+
+func newServiceInfo(service ServiceBackends) *localinterface.ServiceInfo {
+	now := time.Now()
+	res := &localinterface.ServiceInfo{
+		Name:               service.Name,
+		Address:            service.Address,
+		Type:               service.Type,
+		Scheduler:          service.SchedName,
+		Forward:            service.Forward,
+		Runtime:            service.Runtime,
+		Source:             service.Source,
+		PersistenceTimeout: int32(service.PersistenceTimeout),
+		Netmask:            service.Netmask,
+		SchedFlags:         service.SchedFlags,
+		OnePacket:          service.IsOnePacket(),
+		Backends:           make([]*localinterface.Backend, len(service.Backends)),
+	}
+	for idx, backend := range service.Backends {
+		res.Backends[idx] = &localinterface.Backend{
+			Address:        backend.Address,
+			Weight:         int32(backend.Weight),
+			AdditionalInfo: backend.AdditionalInfo,
+			State:          backendState(backend, now),
+			UpperThreshold: int32(backend.UpperThreshold),
+			LowerThreshold: int32(backend.LowerThreshold),
+		}
+	}
+	return res
+}
+
+// ListServices returns all services with their live backends
+func (s *Service) ListServices(context.Context, *localinterface.Empty) (*localinterface.ServiceList, error) {
+	res := &localinterface.ServiceList{
+		Services: make([]*localinterface.ServiceInfo, 0),
+	}
+	if s.IPVSApplier == nil {
+		return res, nil
+	}
+
+	for _, service := range s.IPVSApplier.Services() {
+		res.Services = append(res.Services, newServiceInfo(service))
+	}
+	return res, nil
+}
+
+// GetService returns a single service by name with its live backends
+func (s *Service) GetService(ctx context.Context, req *localinterface.ServiceRequest) (*localinterface.ServiceInfo, error) {
+	if s.IPVSApplier != nil {
+		for _, service := range s.IPVSApplier.Services() {
+			if service.Name == req.Name {
+				return newServiceInfo(service), nil
+			}
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "no such service: %s", req.Name)
+}
+
+// DrainBackend sets the weight of a backend to 0, and optionally removes it
+// after a grace period, until it is enabled again.
+func (s *Service) DrainBackend(ctx context.Context, req *localinterface.BackendRequest) (*localinterface.Empty, error) {
+	if s.IPVSApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no ipvs applier active")
+	}
+	err := s.IPVSApplier.DrainBackend(req.Service, req.Address, time.Duration(req.RemoveAfterSecs)*time.Second)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &localinterface.Empty{}, nil
+}
+
+// EnableBackend clears a runtime override of a backend
+func (s *Service) EnableBackend(ctx context.Context, req *localinterface.BackendRequest) (*localinterface.Empty, error) {
+	if s.IPVSApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no ipvs applier active")
+	}
+	if err := s.IPVSApplier.EnableBackend(req.Service, req.Address); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &localinterface.Empty{}, nil
+}
+
+// AddService registers a service at runtime. The request contains
+// a single service definition in yaml, as in the configuration file.
+func (s *Service) AddService(ctx context.Context, req *localinterface.AddServiceRequest) (*localinterface.Empty, error) {
+	if s.ConfigApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no config applier active")
+	}
+
+	service, err := config.ReadServiceFromBytes(req.Service)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unable to parse service: %s", err)
+	}
+	if service.Name == "" || service.Address == "" || service.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "service needs a name, address and type")
+	}
+
+	switch err := s.ConfigApplier.AddService(ctx, service); err {
+	case nil:
+		return &localinterface.Empty{}, nil
+	case errNotConfigured:
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errServiceExists:
+		return nil, status.Errorf(codes.AlreadyExists, "%s: %s", err, service.Name)
+	default:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+// RemoveService removes a service that has been registered at runtime
+func (s *Service) RemoveService(ctx context.Context, req *localinterface.ServiceRequest) (*localinterface.Empty, error) {
+	if s.ConfigApplier == nil {
+		return nil, status.Error(codes.Unavailable, "no config applier active")
+	}
+
+	switch err := s.ConfigApplier.RemoveService(ctx, req.Name); err {
+	case nil:
+		return &localinterface.Empty{}, nil
+	case errNotConfigured, errNotRuntimeOwned:
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: %s", err, req.Name)
+	case errNoSuchService:
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err, req.Name)
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+}
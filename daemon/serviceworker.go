@@ -4,11 +4,15 @@ import (
 	"container/list"
 	"sort"
 	"sync"
+	"time"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	log "github.com/sirupsen/logrus"
 )
 
+var logServiceWorker = logging.Component("serviceworker")
+
 // ServiceWorker takes care about a single service of the
 // current configuration model.
 type ServiceWorker struct {
@@ -18,10 +22,39 @@ type ServiceWorker struct {
 
 	cfg     *model.IPVSMeshConfig
 	service *model.Service
+
+	// results of last plugin query, for status reporting
+	mu          sync.Mutex
+	lastUpdate  time.Time
+	lastError   error
+	numBackends int
+
+	// state of the plugin's notification loop
+	hasLoop     bool
+	loopRunning bool
+	loopErr     error
+}
+
+// ServiceWorkerStatus is a snapshot of a service worker's state
+type ServiceWorkerStatus struct {
+	Name        string
+	Type        string
+	Address     string
+	Runtime     bool
+	Source      string
+	NumBackends int
+	LastUpdate  time.Time
+	LastError   error
+
+	Alive                   bool
+	HasNotificationLoop     bool
+	NotificationLoopRunning bool
+	NotificationLoopErr     error
 }
 
 var (
-	serviceWorkerList *list.List
+	serviceWorkerList   *list.List
+	serviceWorkerListMu sync.Mutex
 )
 
 // GetAllServiceWorkers returns a list of all services
@@ -36,6 +69,9 @@ func GetAllServiceWorkers() *list.List {
 // GetServiceWorkerByName retrieves a single ServiceWorker
 // by the name of its service within the model.
 func GetServiceWorkerByName(name string) *ServiceWorker {
+	serviceWorkerListMu.Lock()
+	defer serviceWorkerListMu.Unlock()
+
 	l := GetAllServiceWorkers()
 	for e := l.Front(); e != nil; e = e.Next() {
 		sw := e.Value.(*ServiceWorker)
@@ -46,20 +82,82 @@ func GetServiceWorkerByName(name string) *ServiceWorker {
 	return nil
 }
 
+// removeServiceWorkers removes all service workers for which remove
+// returns true from the list of active workers, and returns them. The
+// caller stops them, without holding serviceWorkerListMu.
+func removeServiceWorkers(remove func(sw *ServiceWorker) bool) []*ServiceWorker {
+	serviceWorkerListMu.Lock()
+	defer serviceWorkerListMu.Unlock()
+
+	res := make([]*ServiceWorker, 0)
+	l := GetAllServiceWorkers()
+	for e := l.Front(); e != nil; {
+		next := e.Next()
+		sw := e.Value.(*ServiceWorker)
+		if remove(sw) {
+			res = append(res, sw)
+			l.Remove(e)
+		}
+		e = next
+	}
+	return res
+}
+
 // NewServiceWorker creates a new ServiceWorker for a single service of a configuration model.
-func NewServiceWorker(sc chan *sync.WaitGroup, cfg *model.IPVSMeshConfig, service *model.Service, ipvsUpdateChan IPVSApplierChanType) *ServiceWorker {
+// Each worker has its own stop channel, so that a single service can be taken down.
+func NewServiceWorker(cfg *model.IPVSMeshConfig, service *model.Service, ipvsUpdateChan IPVSApplierChanType) *ServiceWorker {
+	sc := make(chan *sync.WaitGroup, 1)
 	sw := &ServiceWorker{
 		StoppableByChan: StoppableByChan{
 			StopChan: &sc,
+			Name:     "serviceworker:" + service.Name,
 		},
 		cfg:            cfg,
 		service:        service,
 		ipvsUpdateChan: ipvsUpdateChan,
 	}
+	serviceWorkerListMu.Lock()
 	GetAllServiceWorkers().PushBack(sw)
+	serviceWorkerListMu.Unlock()
 	return sw
 }
 
+// GetServiceWorkerStatus returns status snapshots of all active
+// service workers.
+func GetServiceWorkerStatus() []ServiceWorkerStatus {
+	serviceWorkerListMu.Lock()
+	defer serviceWorkerListMu.Unlock()
+
+	l := GetAllServiceWorkers()
+	res := make([]ServiceWorkerStatus, 0, l.Len())
+	for e := l.Front(); e != nil; e = e.Next() {
+		res = append(res, e.Value.(*ServiceWorker).Status())
+	}
+	return res
+}
+
+// Status returns a snapshot of this service worker's state
+func (s *ServiceWorker) Status() ServiceWorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return ServiceWorkerStatus{
+		Name:        s.service.Name,
+		Type:        s.service.Type,
+		Address:     s.service.Address,
+		Runtime:     s.service.Runtime,
+		Source:      s.service.Source,
+		NumBackends: s.numBackends,
+		LastUpdate:  s.lastUpdate,
+		LastError:   s.lastError,
+
+		Alive:                   s.Alive(),
+		HasNotificationLoop:     s.hasLoop,
+		NotificationLoopRunning: s.loopRunning,
+		NotificationLoopErr:     s.loopErr,
+	}
+}
+
 type byAddress []model.DownwardBackendServer
 
 func (a byAddress) Len() int           { return len(a) }
@@ -71,12 +169,13 @@ func (s *ServiceWorker) queryAndProcessDownwardData() {
 
 	data, err := p.GetDownwardData()
 	if err != nil {
-		log.WithFields(log.Fields{
+		metricPluginErrors.WithLabelValues(s.service.Type).Inc()
+		logServiceWorker.WithFields(log.Fields{
 			"err":     err,
 			"service": s.service.Name,
 		}).Error("serviceworker: Unable to get downward data from plugin")
 	}
-	log.WithFields(log.Fields{
+	logServiceWorker.WithFields(log.Fields{
 		"data":    data,
 		"service": s.service.Name,
 	}).Info("serviceworker: Received backend updates")
@@ -84,6 +183,12 @@ func (s *ServiceWorker) queryAndProcessDownwardData() {
 	// sort by address
 	sort.Sort(byAddress(data))
 
+	s.mu.Lock()
+	s.lastUpdate = time.Now()
+	s.lastError = err
+	s.numBackends = len(data)
+	s.mu.Unlock()
+
 	// forward them
 	s.ipvsUpdateChan <- IPVSApplierUpdateStruct{
 		serviceName: s.service.Name,
@@ -95,7 +200,9 @@ func (s *ServiceWorker) queryAndProcessDownwardData() {
 
 // Worker checks downward notifications
 func (s *ServiceWorker) Worker() {
-	log.WithField("Name", s.service.Name).Info("serviceworker: Starting service worker...")
+	logServiceWorker.WithField("Name", s.service.Name).Info("serviceworker: Starting service worker...")
+	emitEvent(EventServiceWorkerStarted, s.service.Name, "Started service worker", map[string]string{"type": s.service.Type})
+	defer s.markExited()
 
 	s.queryAndProcessDownwardData()
 
@@ -105,19 +212,38 @@ func (s *ServiceWorker) Worker() {
 	if p != nil {
 		if p.HasDownwardInterface() {
 			// set up notification
-			go p.RunNotificationLoop(updateCh, quitCh)
+			s.mu.Lock()
+			s.hasLoop, s.loopRunning = true, true
+			s.mu.Unlock()
+
+			name := s.service.Name
+			go func() {
+				err := p.RunNotificationLoop(updateCh, quitCh)
+				if err != nil {
+					logServiceWorker.WithFields(log.Fields{
+						"err":     err,
+						"service": name,
+					}).Error("serviceworker: Notification loop of plugin stopped")
+				}
+				s.mu.Lock()
+				s.loopRunning, s.loopErr = false, err
+				s.mu.Unlock()
+			}()
 		}
 	}
 
 	for {
 		select {
 		case <-updateCh:
+			metricPluginNotifications.WithLabelValues(s.service.Type).Inc()
 			s.queryAndProcessDownwardData()
 
 		case wg := <-*s.StoppableByChan.StopChan:
-			log.WithField("Name", s.service.Name).Info("serviceworker: Stopping service worker")
+			logServiceWorker.WithField("Name", s.service.Name).Info("serviceworker: Stopping service worker")
+			emitEvent(EventServiceWorkerStopped, s.service.Name, "Stopped service worker", nil)
+			// close instead of send, there may be no notification loop listening
+			close(quitCh)
 			wg.Done()
-			quitCh <- struct{}{}
 			return
 		}
 	}
@@ -125,9 +251,12 @@ func (s *ServiceWorker) Worker() {
 
 // Update applies configuration updates to a ServiceWorker
 func (s *ServiceWorker) Update(newService *model.Service) {
-	log.WithField("Name", s.service.Name).Info("serviceworker: Updating service...")
+	logServiceWorker.WithField("Name", s.service.Name).Info("serviceworker: Updating service...")
 	// TODO: apply new parts here..
+	s.mu.Lock()
 	s.service = newService
+	s.mu.Unlock()
 	s.queryAndProcessDownwardData()
-	log.WithField("data", s.service).Info("serviceworker: Updated service.")
+	logServiceWorker.WithField("Name", newService.Name).Info("serviceworker: Updated service.")
+	emitEvent(EventServiceWorkerUpdated, newService.Name, "Updated service worker", nil)
 }
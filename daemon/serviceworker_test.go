@@ -0,0 +1,30 @@
+package daemon
+
+import (
+	"container/list"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+func TestRemoveServiceWorkers(t *testing.T) {
+	serviceWorkerList = list.New()
+	defer func() { serviceWorkerList = nil }()
+
+	for _, name := range []string{"a", "b", "c"} {
+		NewServiceWorker(&model.IPVSMeshConfig{}, &model.Service{Name: name}, nil)
+	}
+
+	removed := removeServiceWorkers(func(sw *ServiceWorker) bool { return sw.service.Name != "b" })
+	if len(removed) != 2 || removed[0].service.Name != "a" || removed[1].service.Name != "c" {
+		t.Errorf("got %d removed workers, expected a and c", len(removed))
+	}
+	if GetServiceWorkerByName("b") == nil || GetAllServiceWorkers().Len() != 1 {
+		t.Errorf("expected only b to remain, got %d workers", GetAllServiceWorkers().Len())
+	}
+
+	removed = removeServiceWorkers(func(sw *ServiceWorker) bool { return true })
+	if len(removed) != 1 || GetAllServiceWorkers().Len() != 0 {
+		t.Errorf("got %d removed and %d remaining workers", len(removed), GetAllServiceWorkers().Len())
+	}
+}
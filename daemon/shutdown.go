@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// shutdownPolicy returns the configured shutdown policy and drain period
+func shutdownPolicy(globals *model.Globals) (string, time.Duration, error) {
+	policy := globals.Shutdown.Policy
+	switch policy {
+	case "":
+		return model.ShutdownKeep, 0, nil
+	case model.ShutdownKeep, model.ShutdownFlush:
+		return policy, 0, nil
+	case model.ShutdownDrain:
+		if globals.Shutdown.DrainPeriod == "" {
+			return policy, 0, nil
+		}
+		d, err := time.ParseDuration(globals.Shutdown.DrainPeriod)
+		if err != nil {
+			return policy, 0, fmt.Errorf("invalid shutdown drain period: %s", err)
+		}
+		return policy, d, nil
+	default:
+		return policy, 0, fmt.Errorf("unknown shutdown policy: %s", policy)
+	}
+}
+
+// ShutdownPeriod returns how long applying the shutdown policy may take
+func (s *IPVSApplierWorker) ShutdownPeriod() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg == nil {
+		return 0
+	}
+	_, d, _ := shutdownPolicy(&s.cfg.Globals)
+	return d
+}
+
+// shutdown applies the configured shutdown policy to the services
+// of the most recently applied model. Nothing is done while paused.
+func (s *IPVSApplierWorker) shutdown() {
+	s.mu.Lock()
+	cfg, paused, applied := s.cfg, s.paused, s.appliedModel
+	s.mu.Unlock()
+
+	if cfg == nil || applied == nil {
+		return
+	}
+	policy, drainPeriod, err := shutdownPolicy(&cfg.Globals)
+	if err != nil {
+		logIPVSApplier.WithField("err", err).Error("ipvsapplier: Invalid shutdown policy, keeping services")
+		return
+	}
+	if policy == model.ShutdownKeep {
+		return
+	}
+	if paused {
+		logIPVSApplier.WithField("policy", policy).Warn("ipvsapplier: Paused, keeping services instead of applying shutdown policy")
+		return
+	}
+
+	if policy == model.ShutdownDrain {
+		logIPVSApplier.WithField("drainPeriod", drainPeriod).Info("ipvsapplier: Draining services before shutdown")
+		if err := s.applyUpdate(drainedModel(applied)); err != nil {
+			logIPVSApplier.WithField("err", err).Error("ipvsapplier: Unable to drain services")
+		}
+		time.Sleep(drainPeriod)
+	}
+
+	logIPVSApplier.Info("ipvsapplier: Removing services before shutdown")
+	if err := s.applyUpdate(flushedModel(applied)); err != nil {
+		logIPVSApplier.WithField("err", err).Error("ipvsapplier: Unable to remove services")
+	}
+}
+
+// isMeshService returns true if a service of an ipvsctl model has been created by ipvsmesh
+func isMeshService(service map[string]interface{}) bool {
+	_, ex := service["ipvsmesh.service.name"]
+	return ex
+}
+
+// flushedModel returns a copy of m without all services created by ipvsmesh.
+// The applied model only contains services of ipvsmesh, and ipvsctl apply
+// removes all services that are not part of the model, so this flushes the
+// ipvs table entirely.
+func flushedModel(m model.IPVSModelStruct) model.IPVSModelStruct {
+	res := make(model.IPVSModelStruct, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+
+	services, _ := m["services"].([]interface{})
+	remaining := make([]interface{}, 0, len(services))
+	for _, serviceRaw := range services {
+		service, ok := serviceRaw.(map[string]interface{})
+		if ok && isMeshService(service) {
+			continue
+		}
+		remaining = append(remaining, serviceRaw)
+	}
+	res["services"] = remaining
+
+	return res
+}
+
+// drainedModel returns a copy of m with weights of all destinations
+// of services created by ipvsmesh set to 0
+func drainedModel(m model.IPVSModelStruct) model.IPVSModelStruct {
+	res := make(model.IPVSModelStruct, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+
+	services, _ := m["services"].([]interface{})
+	drained := make([]interface{}, 0, len(services))
+	for _, serviceRaw := range services {
+		service, ok := serviceRaw.(map[string]interface{})
+		if !ok || !isMeshService(service) {
+			drained = append(drained, serviceRaw)
+			continue
+		}
+
+		ds := make(map[string]interface{}, len(service))
+		for k, v := range service {
+			ds[k] = v
+		}
+		destinations, _ := service["destinations"].([]interface{})
+		dds := make([]interface{}, 0, len(destinations))
+		for _, destinationRaw := range destinations {
+			destination, ok := destinationRaw.(map[string]interface{})
+			if !ok {
+				dds = append(dds, destinationRaw)
+				continue
+			}
+			dd := make(map[string]interface{}, len(destination))
+			for k, v := range destination {
+				dd[k] = v
+			}
+			dd["weight"] = 0
+			dds = append(dds, dd)
+		}
+		ds["destinations"] = dds
+		drained = append(drained, ds)
+	}
+	res["services"] = drained
+
+	return res
+}
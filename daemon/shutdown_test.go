@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+)
+
+// shutdownTestModel returns a model with a service of ipvsmesh
+// and a foreign service
+func shutdownTestModel() model.IPVSModelStruct {
+	return model.IPVSModelStruct{
+		"services": []interface{}{
+			map[string]interface{}{
+				"address":               "tcp://10.0.0.1:80",
+				"ipvsmesh.service.name": "web",
+				"destinations": []interface{}{
+					map[string]interface{}{"address": "tcp://20.0.0.1:80", "weight": 1000},
+					map[string]interface{}{"address": "tcp://20.0.0.2:80", "weight": 500},
+				},
+			},
+			map[string]interface{}{
+				"address": "tcp://10.0.0.2:80",
+				"destinations": []interface{}{
+					map[string]interface{}{"address": "tcp://20.0.0.3:80", "weight": 1000},
+				},
+			},
+		},
+	}
+}
+
+func TestFlushedModel(t *testing.T) {
+	m := shutdownTestModel()
+	res := flushedModel(m)
+
+	expected := model.IPVSModelStruct{
+		"services": []interface{}{
+			shutdownTestModel()["services"].([]interface{})[1],
+		},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %v, expected %v", res, expected)
+	}
+	if !reflect.DeepEqual(m, shutdownTestModel()) {
+		t.Error("flushedModel modified its input")
+	}
+
+	if res := flushedModel(model.IPVSModelStruct{}); !reflect.DeepEqual(res, model.IPVSModelStruct{"services": []interface{}{}}) {
+		t.Errorf("got %v for an empty model", res)
+	}
+}
+
+func TestDrainedModel(t *testing.T) {
+	m := shutdownTestModel()
+	res := drainedModel(m)
+
+	expected := shutdownTestModel()
+	for _, d := range expected["services"].([]interface{})[0].(map[string]interface{})["destinations"].([]interface{}) {
+		d.(map[string]interface{})["weight"] = 0
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %v, expected %v", res, expected)
+	}
+	if !reflect.DeepEqual(m, shutdownTestModel()) {
+		t.Error("drainedModel modified its input")
+	}
+}
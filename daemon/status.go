@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/localinterface"
+)
+
+// Status reports uptime, configuration, active service workers,
+// publishers and the outcome of the last ipvsctl apply.
+func (s *Service) Status(context.Context, *localinterface.Empty) (*localinterface.StatusResponse, error) {
+	res := &localinterface.StatusResponse{
+		StartTime:  s.startTime.Unix(),
+		UptimeSecs: int64(time.Since(s.startTime).Seconds()),
+		Services:   make([]*localinterface.ServiceWorkerStatus, 0),
+		Publishers: make([]*localinterface.PublisherStatus, 0),
+		Overrides:  make([]*localinterface.BackendOverrideStatus, 0),
+		LastApply:  &localinterface.ApplyStatus{},
+	}
+
+	if s.ConfigWatcher != nil {
+		fileName, modTime := s.ConfigWatcher.ConfigFile()
+		res.ConfigFile = fileName
+		res.ConfigModTime = unixOrZero(modTime)
+	}
+
+	for _, sws := range GetServiceWorkerStatus() {
+		sw := &localinterface.ServiceWorkerStatus{
+			Name:        sws.Name,
+			Type:        sws.Type,
+			Address:     sws.Address,
+			Runtime:     sws.Runtime,
+			Source:      sws.Source,
+			NumBackends: int32(sws.NumBackends),
+			LastUpdate:  unixOrZero(sws.LastUpdate),
+		}
+		if sws.LastError != nil {
+			sw.LastError = sws.LastError.Error()
+		}
+		res.Services = append(res.Services, sw)
+	}
+	sort.Slice(res.Services, func(i, j int) bool { return res.Services[i].Name < res.Services[j].Name })
+
+	if s.Publisher != nil {
+		for _, publisher := range s.Publisher.Publishers() {
+			res.Publishers = append(res.Publishers, &localinterface.PublisherStatus{
+				Name:        publisher.Name,
+				Type:        publisher.Type,
+				MatchLabels: publisher.MatchLabels,
+			})
+		}
+		sort.Slice(res.Publishers, func(i, j int) bool { return res.Publishers[i].Name < res.Publishers[j].Name })
+	}
+
+	if s.IPVSApplier != nil {
+		la := s.IPVSApplier.LastApply()
+		res.LastApply = &localinterface.ApplyStatus{
+			Time:           unixOrZero(la.Time),
+			Success:        !la.Time.IsZero() && la.Err == nil,
+			ExecutionType:  la.ExecType,
+			DurationMillis: int64(la.Duration / time.Millisecond),
+			NumServices:    int32(la.NumServices),
+		}
+		if la.Err != nil {
+			res.LastApply.Error = la.Err.Error()
+		}
+
+		paused, pausedSince, pending := s.IPVSApplier.Paused()
+		res.Paused = paused
+		res.PendingChanges = pending
+		if paused {
+			res.PausedSince = unixOrZero(pausedSince)
+		}
+
+		now := time.Now()
+		for _, o := range s.IPVSApplier.Overrides() {
+			res.Overrides = append(res.Overrides, &localinterface.BackendOverrideStatus{
+				Service:  o.Service,
+				Address:  o.Address,
+				Weight:   int32(o.Weight),
+				Since:    unixOrZero(o.Since),
+				RemoveAt: unixOrZero(o.RemoveAt),
+				Removed:  o.Removed(now),
+			})
+		}
+	}
+
+	return res, nil
+}
+
+// unixOrZero returns the unix timestamp of t, or 0 for the zero time
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/systemd"
+	log "github.com/sirupsen/logrus"
+)
+
+// sdNotify sends a state to systemd, if started by it as Type=notify
+func sdNotify(state string) {
+	if _, err := systemd.Notify(state); err != nil {
+		log.WithFields(log.Fields{
+			"err":   err,
+			"state": state,
+		}).Warn("daemon: Unable to notify systemd")
+	}
+}
+
+// notifySystemd reports readiness once Readiness is ok, a status
+// line with service counts and, if enabled, watchdog keep-alives as long as all
+// workers are alive. It returns when the service is stopping.
+func (s *Service) notifySystemd() {
+	if ok, _ := systemd.Notify(systemd.Status("starting")); !ok {
+		// not started by systemd
+		return
+	}
+
+	interval := time.Second
+	watchdogInterval, watchdog := systemd.WatchdogInterval()
+	if watchdog && watchdogInterval/2 < interval {
+		interval = watchdogInterval / 2
+	}
+	log.WithFields(log.Fields{
+		"watchdog": watchdog,
+		"interval": watchdogInterval,
+	}).Debug("daemon: Notifying systemd")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ready := false
+	lastStatus := ""
+	for {
+		select {
+		case <-ticker.C:
+			if !ready && s.Readiness().Status == "ok" {
+				sdNotify(systemd.Ready)
+				ready = true
+			}
+
+			if status := s.systemdStatus(ready); status != lastStatus {
+				sdNotify(systemd.Status(status))
+				lastStatus = status
+			}
+
+			if watchdog {
+				if liveness := s.Liveness(); liveness.Status == "ok" {
+					sdNotify(systemd.Watchdog)
+				} else {
+					log.WithField("failing", liveness.Failing).Warn("daemon: Not sending watchdog keep-alive, components are failing")
+				}
+			}
+
+		case <-s.stopping:
+			return
+		}
+	}
+}
+
+// systemdStatus returns a one-line status for systemctl status
+func (s *Service) systemdStatus(ready bool) string {
+	if !ready {
+		return "waiting for configuration and first apply"
+	}
+
+	numServices, numBackends := 0, 0
+	if s.IPVSApplier != nil {
+		for _, service := range s.IPVSApplier.Services() {
+			numServices++
+			numBackends += len(service.Backends)
+		}
+		if paused, _, _ := s.IPVSApplier.Paused(); paused {
+			return fmt.Sprintf("paused, %d services, %d backends", numServices, numBackends)
+		}
+	}
+	return fmt.Sprintf("%d services, %d backends", numServices, numBackends)
+}
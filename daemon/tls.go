@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// newServerCredentials loads the server key pair. If caFile is given, clients
+// must present a certificate signed by that CA.
+func newServerCredentials(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if caFile != "" {
+		b, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New("no CA certificates found in " + caFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// clientCN returns the common name of the verified client certificate
+// of a call, or an empty string if the client did not present one.
+func clientCN(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	for _, chain := range tlsInfo.State.VerifiedChains {
+		if len(chain) > 0 {
+			return chain[0].Subject.CommonName
+		}
+	}
+	return ""
+}
+
+// logUnaryCall logs every unary call together with the client's CN
+func logUnaryCall(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	logCall(ctx, info.FullMethod)
+	return handler(ctx, req)
+}
+
+// logStreamCall logs every streaming call together with the client's CN
+func logStreamCall(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	logCall(ss.Context(), info.FullMethod)
+	return handler(srv, ss)
+}
+
+func logCall(ctx context.Context, method string) {
+	if cn := clientCN(ctx); cn != "" {
+		log.WithFields(log.Fields{
+			"method": method,
+			"client": cn,
+		}).Info("daemon: RPC call")
+		return
+	}
+	log.WithField("method", method).Debug("daemon: RPC call")
+}
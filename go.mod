@@ -6,12 +6,13 @@ require (
 	github.com/caarlos0/env/v6 v6.0.0
 	github.com/docker/distribution v2.7.1+incompatible // indirect
 	github.com/docker/docker v1.13.1
-	github.com/docker/go-connections v0.4.0 // indirect
+	github.com/docker/go-connections v0.4.0
 	github.com/docker/go-units v0.4.0 // indirect
 	github.com/golang/protobuf v1.3.1
 	github.com/jawher/mow.cli v1.1.0
 	github.com/opencontainers/go-digest v1.0.0-rc1 // indirect
 	github.com/pkg/errors v0.8.1 // indirect
+	github.com/prometheus/client_golang v1.0.0
 	github.com/radovskyb/watcher v1.0.7
 	github.com/sirupsen/logrus v1.4.2
 	golang.org/x/crypto v0.0.0-20190611184440-5c40567a22f8 // indirect
@@ -21,5 +22,6 @@ require (
 	golang.org/x/tools v0.0.0-20191023163450-98e333b8b3a3 // indirect
 	google.golang.org/grpc v1.21.1
 	gopkg.in/yaml.v2 v2.2.4
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 	honnef.co/go/tools v0.0.1-2019.2.3
 )
@@ -21,7 +21,7 @@ func main() {
 
 	app.Version("version", Version)
 
-	app.Spec = "[-d] [-v] [--trace] [--tls --tlscert=<certfile>] [--tlskey=<keyfile>]"
+	app.Spec = "[-d] [-v] [--trace] [--tls --tlscert=<certfile>] [--tlskey=<keyfile>] [--tlsca=<cafile>] [--host=<address>]"
 
 	trace := app.BoolOpt("trace", c.Trace, "Show trace messages")
 	debug := app.BoolOpt("d debug", c.Debug, "Show debug messages")
@@ -29,8 +29,15 @@ func main() {
 	tls := app.BoolOpt("tls", false, "Use TLS for daemon communication. Needs --tlscert and --tlskey")
 	tlscert := app.StringOpt("tlscert", "", "TLS certificate file in PEM format. Valid only with --tls and --tlskey")
 	tlskey := app.StringOpt("tlskey", "", "TLS key file in PEM format. Valid only with --tls and --tlskcert")
+	host := app.StringOpt("host", c.DaemonHost, "TCP address of a remote daemon, e.g. lb1.example.com:7443. Requires --tls")
+	tlsca := app.StringOpt("tlsca", c.TLSCAFile, "TLS CA certificate file in PEM format. Daemon requires client certificates signed by it, clients verify the daemon with it")
 
 	app.Command("daemon", "manages the background daemon.", cmd.Daemon)
+	app.Command("service", "queries services and their backends from the daemon.", cmd.Service)
+	app.Command("events", "shows events of the daemon.", cmd.Events)
+	app.Command("backend", "controls backends of services at runtime.", cmd.Backend)
+	app.Command("config", "works with configuration files.", cmd.Config)
+	app.Command("pki", "manages a CA and certificates for TLS.", cmd.PKI)
 
 	app.Before = func() {
 		if trace != nil {
@@ -44,6 +51,8 @@ func main() {
 		}
 		logging.InitLogging(c.Trace, c.Debug, c.Verbose)
 
+		c.DaemonHost = *host
+
 		c.TLS = *tls
 		if c.TLS {
 			if *tlscert == "" {
@@ -52,6 +61,7 @@ func main() {
 
 			c.TLSCertFile = *tlscert
 			c.TLSKeyFile = *tlskey
+			c.TLSCAFile = *tlsca
 		}
 	}
 	app.Run(os.Args)
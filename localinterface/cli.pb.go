@@ -54,49 +54,1628 @@ func (m *Empty) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
+// ServiceWorkerStatus describes a single active service worker
+type ServiceWorkerStatus struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Address              string   `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	NumBackends          int32    `protobuf:"varint,4,opt,name=numBackends,proto3" json:"numBackends,omitempty"`
+	LastUpdate           int64    `protobuf:"varint,5,opt,name=lastUpdate,proto3" json:"lastUpdate,omitempty"`
+	LastError            string   `protobuf:"bytes,6,opt,name=lastError,proto3" json:"lastError,omitempty"`
+	Runtime              bool     `protobuf:"varint,7,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	Source               string   `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServiceWorkerStatus) Reset()         { *m = ServiceWorkerStatus{} }
+func (m *ServiceWorkerStatus) String() string { return proto.CompactTextString(m) }
+func (*ServiceWorkerStatus) ProtoMessage()    {}
+func (*ServiceWorkerStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{1}
+}
+
+func (m *ServiceWorkerStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceWorkerStatus.Unmarshal(m, b)
+}
+func (m *ServiceWorkerStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceWorkerStatus.Marshal(b, m, deterministic)
+}
+func (m *ServiceWorkerStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceWorkerStatus.Merge(m, src)
+}
+func (m *ServiceWorkerStatus) XXX_Size() int {
+	return xxx_messageInfo_ServiceWorkerStatus.Size(m)
+}
+func (m *ServiceWorkerStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceWorkerStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceWorkerStatus proto.InternalMessageInfo
+
+func (m *ServiceWorkerStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ServiceWorkerStatus) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ServiceWorkerStatus) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *ServiceWorkerStatus) GetNumBackends() int32 {
+	if m != nil {
+		return m.NumBackends
+	}
+	return 0
+}
+
+func (m *ServiceWorkerStatus) GetLastUpdate() int64 {
+	if m != nil {
+		return m.LastUpdate
+	}
+	return 0
+}
+
+func (m *ServiceWorkerStatus) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *ServiceWorkerStatus) GetRuntime() bool {
+	if m != nil {
+		return m.Runtime
+	}
+	return false
+}
+
+func (m *ServiceWorkerStatus) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+// PublisherStatus describes a registered publisher
+type PublisherStatus struct {
+	Name                 string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type                 string            `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	MatchLabels          map[string]string `protobuf:"bytes,3,rep,name=matchLabels,proto3" json:"matchLabels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PublisherStatus) Reset()         { *m = PublisherStatus{} }
+func (m *PublisherStatus) String() string { return proto.CompactTextString(m) }
+func (*PublisherStatus) ProtoMessage()    {}
+func (*PublisherStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{2}
+}
+
+func (m *PublisherStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublisherStatus.Unmarshal(m, b)
+}
+func (m *PublisherStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublisherStatus.Marshal(b, m, deterministic)
+}
+func (m *PublisherStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublisherStatus.Merge(m, src)
+}
+func (m *PublisherStatus) XXX_Size() int {
+	return xxx_messageInfo_PublisherStatus.Size(m)
+}
+func (m *PublisherStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublisherStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublisherStatus proto.InternalMessageInfo
+
+func (m *PublisherStatus) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PublisherStatus) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *PublisherStatus) GetMatchLabels() map[string]string {
+	if m != nil {
+		return m.MatchLabels
+	}
+	return nil
+}
+
+// ApplyStatus is the outcome of the last ipvsctl apply
+type ApplyStatus struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Success              bool     `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	ExecutionType        string   `protobuf:"bytes,4,opt,name=executionType,proto3" json:"executionType,omitempty"`
+	DurationMillis       int64    `protobuf:"varint,5,opt,name=durationMillis,proto3" json:"durationMillis,omitempty"`
+	NumServices          int32    `protobuf:"varint,6,opt,name=numServices,proto3" json:"numServices,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ApplyStatus) Reset()         { *m = ApplyStatus{} }
+func (m *ApplyStatus) String() string { return proto.CompactTextString(m) }
+func (*ApplyStatus) ProtoMessage()    {}
+func (*ApplyStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{3}
+}
+
+func (m *ApplyStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ApplyStatus.Unmarshal(m, b)
+}
+func (m *ApplyStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ApplyStatus.Marshal(b, m, deterministic)
+}
+func (m *ApplyStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ApplyStatus.Merge(m, src)
+}
+func (m *ApplyStatus) XXX_Size() int {
+	return xxx_messageInfo_ApplyStatus.Size(m)
+}
+func (m *ApplyStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ApplyStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ApplyStatus proto.InternalMessageInfo
+
+func (m *ApplyStatus) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *ApplyStatus) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *ApplyStatus) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ApplyStatus) GetExecutionType() string {
+	if m != nil {
+		return m.ExecutionType
+	}
+	return ""
+}
+
+func (m *ApplyStatus) GetDurationMillis() int64 {
+	if m != nil {
+		return m.DurationMillis
+	}
+	return 0
+}
+
+func (m *ApplyStatus) GetNumServices() int32 {
+	if m != nil {
+		return m.NumServices
+	}
+	return 0
+}
+
+// BackendOverrideStatus describes a runtime override of a backend
+type BackendOverrideStatus struct {
+	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Weight               int32    `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	Since                int64    `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	RemoveAt             int64    `protobuf:"varint,5,opt,name=removeAt,proto3" json:"removeAt,omitempty"`
+	Removed              bool     `protobuf:"varint,6,opt,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackendOverrideStatus) Reset()         { *m = BackendOverrideStatus{} }
+func (m *BackendOverrideStatus) String() string { return proto.CompactTextString(m) }
+func (*BackendOverrideStatus) ProtoMessage()    {}
+func (*BackendOverrideStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{4}
+}
+
+func (m *BackendOverrideStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackendOverrideStatus.Unmarshal(m, b)
+}
+func (m *BackendOverrideStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackendOverrideStatus.Marshal(b, m, deterministic)
+}
+func (m *BackendOverrideStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackendOverrideStatus.Merge(m, src)
+}
+func (m *BackendOverrideStatus) XXX_Size() int {
+	return xxx_messageInfo_BackendOverrideStatus.Size(m)
+}
+func (m *BackendOverrideStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackendOverrideStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackendOverrideStatus proto.InternalMessageInfo
+
+func (m *BackendOverrideStatus) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *BackendOverrideStatus) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *BackendOverrideStatus) GetWeight() int32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *BackendOverrideStatus) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *BackendOverrideStatus) GetRemoveAt() int64 {
+	if m != nil {
+		return m.RemoveAt
+	}
+	return 0
+}
+
+func (m *BackendOverrideStatus) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+type StatusResponse struct {
+	StartTime            int64                    `protobuf:"varint,1,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	UptimeSecs           int64                    `protobuf:"varint,2,opt,name=uptimeSecs,proto3" json:"uptimeSecs,omitempty"`
+	ConfigFile           string                   `protobuf:"bytes,3,opt,name=configFile,proto3" json:"configFile,omitempty"`
+	ConfigModTime        int64                    `protobuf:"varint,4,opt,name=configModTime,proto3" json:"configModTime,omitempty"`
+	Services             []*ServiceWorkerStatus   `protobuf:"bytes,5,rep,name=services,proto3" json:"services,omitempty"`
+	Publishers           []*PublisherStatus       `protobuf:"bytes,6,rep,name=publishers,proto3" json:"publishers,omitempty"`
+	LastApply            *ApplyStatus             `protobuf:"bytes,7,opt,name=lastApply,proto3" json:"lastApply,omitempty"`
+	Overrides            []*BackendOverrideStatus `protobuf:"bytes,8,rep,name=overrides,proto3" json:"overrides,omitempty"`
+	Paused               bool                     `protobuf:"varint,9,opt,name=paused,proto3" json:"paused,omitempty"`
+	PausedSince          int64                    `protobuf:"varint,10,opt,name=pausedSince,proto3" json:"pausedSince,omitempty"`
+	PendingChanges       bool                     `protobuf:"varint,11,opt,name=pendingChanges,proto3" json:"pendingChanges,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *StatusResponse) Reset()         { *m = StatusResponse{} }
+func (m *StatusResponse) String() string { return proto.CompactTextString(m) }
+func (*StatusResponse) ProtoMessage()    {}
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{5}
+}
+
+func (m *StatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusResponse.Unmarshal(m, b)
+}
+func (m *StatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusResponse.Marshal(b, m, deterministic)
+}
+func (m *StatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusResponse.Merge(m, src)
+}
+func (m *StatusResponse) XXX_Size() int {
+	return xxx_messageInfo_StatusResponse.Size(m)
+}
+func (m *StatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusResponse proto.InternalMessageInfo
+
+func (m *StatusResponse) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *StatusResponse) GetUptimeSecs() int64 {
+	if m != nil {
+		return m.UptimeSecs
+	}
+	return 0
+}
+
+func (m *StatusResponse) GetConfigFile() string {
+	if m != nil {
+		return m.ConfigFile
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetConfigModTime() int64 {
+	if m != nil {
+		return m.ConfigModTime
+	}
+	return 0
+}
+
+func (m *StatusResponse) GetServices() []*ServiceWorkerStatus {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+func (m *StatusResponse) GetPublishers() []*PublisherStatus {
+	if m != nil {
+		return m.Publishers
+	}
+	return nil
+}
+
+func (m *StatusResponse) GetLastApply() *ApplyStatus {
+	if m != nil {
+		return m.LastApply
+	}
+	return nil
+}
+
+func (m *StatusResponse) GetOverrides() []*BackendOverrideStatus {
+	if m != nil {
+		return m.Overrides
+	}
+	return nil
+}
+
+func (m *StatusResponse) GetPaused() bool {
+	if m != nil {
+		return m.Paused
+	}
+	return false
+}
+
+func (m *StatusResponse) GetPausedSince() int64 {
+	if m != nil {
+		return m.PausedSince
+	}
+	return 0
+}
+
+func (m *StatusResponse) GetPendingChanges() bool {
+	if m != nil {
+		return m.PendingChanges
+	}
+	return false
+}
+
+// Backend is a single real server of a service
+type Backend struct {
+	Address              string            `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Weight               int32             `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	AdditionalInfo       map[string]string `protobuf:"bytes,3,rep,name=additionalInfo,proto3" json:"additionalInfo,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	State                string            `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	UpperThreshold       int32             `protobuf:"varint,5,opt,name=upperThreshold,proto3" json:"upperThreshold,omitempty"`
+	LowerThreshold       int32             `protobuf:"varint,6,opt,name=lowerThreshold,proto3" json:"lowerThreshold,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Backend) Reset()         { *m = Backend{} }
+func (m *Backend) String() string { return proto.CompactTextString(m) }
+func (*Backend) ProtoMessage()    {}
+func (*Backend) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{6}
+}
+
+func (m *Backend) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Backend.Unmarshal(m, b)
+}
+func (m *Backend) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Backend.Marshal(b, m, deterministic)
+}
+func (m *Backend) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Backend.Merge(m, src)
+}
+func (m *Backend) XXX_Size() int {
+	return xxx_messageInfo_Backend.Size(m)
+}
+func (m *Backend) XXX_DiscardUnknown() {
+	xxx_messageInfo_Backend.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Backend proto.InternalMessageInfo
+
+func (m *Backend) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Backend) GetWeight() int32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *Backend) GetAdditionalInfo() map[string]string {
+	if m != nil {
+		return m.AdditionalInfo
+	}
+	return nil
+}
+
+func (m *Backend) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Backend) GetUpperThreshold() int32 {
+	if m != nil {
+		return m.UpperThreshold
+	}
+	return 0
+}
+
+func (m *Backend) GetLowerThreshold() int32 {
+	if m != nil {
+		return m.LowerThreshold
+	}
+	return 0
+}
+
+// ServiceInfo describes a service with its live backends
+type ServiceInfo struct {
+	Name                 string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Address              string     `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Type                 string     `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Scheduler            string     `protobuf:"bytes,4,opt,name=scheduler,proto3" json:"scheduler,omitempty"`
+	Forward              string     `protobuf:"bytes,5,opt,name=forward,proto3" json:"forward,omitempty"`
+	Backends             []*Backend `protobuf:"bytes,6,rep,name=backends,proto3" json:"backends,omitempty"`
+	Runtime              bool       `protobuf:"varint,7,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	Source               string     `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	PersistenceTimeout   int32      `protobuf:"varint,9,opt,name=persistenceTimeout,proto3" json:"persistenceTimeout,omitempty"`
+	Netmask              string     `protobuf:"bytes,10,opt,name=netmask,proto3" json:"netmask,omitempty"`
+	SchedFlags           []string   `protobuf:"bytes,11,rep,name=schedFlags,proto3" json:"schedFlags,omitempty"`
+	OnePacket            bool       `protobuf:"varint,12,opt,name=onePacket,proto3" json:"onePacket,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ServiceInfo) Reset()         { *m = ServiceInfo{} }
+func (m *ServiceInfo) String() string { return proto.CompactTextString(m) }
+func (*ServiceInfo) ProtoMessage()    {}
+func (*ServiceInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{7}
+}
+
+func (m *ServiceInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceInfo.Unmarshal(m, b)
+}
+func (m *ServiceInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceInfo.Marshal(b, m, deterministic)
+}
+func (m *ServiceInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceInfo.Merge(m, src)
+}
+func (m *ServiceInfo) XXX_Size() int {
+	return xxx_messageInfo_ServiceInfo.Size(m)
+}
+func (m *ServiceInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceInfo proto.InternalMessageInfo
+
+func (m *ServiceInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetScheduler() string {
+	if m != nil {
+		return m.Scheduler
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetForward() string {
+	if m != nil {
+		return m.Forward
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetBackends() []*Backend {
+	if m != nil {
+		return m.Backends
+	}
+	return nil
+}
+
+func (m *ServiceInfo) GetRuntime() bool {
+	if m != nil {
+		return m.Runtime
+	}
+	return false
+}
+
+func (m *ServiceInfo) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetPersistenceTimeout() int32 {
+	if m != nil {
+		return m.PersistenceTimeout
+	}
+	return 0
+}
+
+func (m *ServiceInfo) GetNetmask() string {
+	if m != nil {
+		return m.Netmask
+	}
+	return ""
+}
+
+func (m *ServiceInfo) GetSchedFlags() []string {
+	if m != nil {
+		return m.SchedFlags
+	}
+	return nil
+}
+
+func (m *ServiceInfo) GetOnePacket() bool {
+	if m != nil {
+		return m.OnePacket
+	}
+	return false
+}
+
+type ServiceList struct {
+	Services             []*ServiceInfo `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ServiceList) Reset()         { *m = ServiceList{} }
+func (m *ServiceList) String() string { return proto.CompactTextString(m) }
+func (*ServiceList) ProtoMessage()    {}
+func (*ServiceList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{8}
+}
+
+func (m *ServiceList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceList.Unmarshal(m, b)
+}
+func (m *ServiceList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceList.Marshal(b, m, deterministic)
+}
+func (m *ServiceList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceList.Merge(m, src)
+}
+func (m *ServiceList) XXX_Size() int {
+	return xxx_messageInfo_ServiceList.Size(m)
+}
+func (m *ServiceList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceList proto.InternalMessageInfo
+
+func (m *ServiceList) GetServices() []*ServiceInfo {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+type ServiceRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServiceRequest) Reset()         { *m = ServiceRequest{} }
+func (m *ServiceRequest) String() string { return proto.CompactTextString(m) }
+func (*ServiceRequest) ProtoMessage()    {}
+func (*ServiceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{9}
+}
+
+func (m *ServiceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceRequest.Unmarshal(m, b)
+}
+func (m *ServiceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceRequest.Marshal(b, m, deterministic)
+}
+func (m *ServiceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceRequest.Merge(m, src)
+}
+func (m *ServiceRequest) XXX_Size() int {
+	return xxx_messageInfo_ServiceRequest.Size(m)
+}
+func (m *ServiceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceRequest proto.InternalMessageInfo
+
+func (m *ServiceRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+// AddServiceRequest contains a single service definition
+// in yaml, as it would appear in the configuration file
+type AddServiceRequest struct {
+	Service              []byte   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddServiceRequest) Reset()         { *m = AddServiceRequest{} }
+func (m *AddServiceRequest) String() string { return proto.CompactTextString(m) }
+func (*AddServiceRequest) ProtoMessage()    {}
+func (*AddServiceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{10}
+}
+
+func (m *AddServiceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddServiceRequest.Unmarshal(m, b)
+}
+func (m *AddServiceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddServiceRequest.Marshal(b, m, deterministic)
+}
+func (m *AddServiceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddServiceRequest.Merge(m, src)
+}
+func (m *AddServiceRequest) XXX_Size() int {
+	return xxx_messageInfo_AddServiceRequest.Size(m)
+}
+func (m *AddServiceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddServiceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddServiceRequest proto.InternalMessageInfo
+
+func (m *AddServiceRequest) GetService() []byte {
+	if m != nil {
+		return m.Service
+	}
+	return nil
+}
+
+// ReloadResponse contains configuration errors if the
+// reloaded configuration has not been applied
+type ReloadResponse struct {
+	Applied              bool     `protobuf:"varint,1,opt,name=applied,proto3" json:"applied,omitempty"`
+	Errors               []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReloadResponse) Reset()         { *m = ReloadResponse{} }
+func (m *ReloadResponse) String() string { return proto.CompactTextString(m) }
+func (*ReloadResponse) ProtoMessage()    {}
+func (*ReloadResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{11}
+}
+
+func (m *ReloadResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReloadResponse.Unmarshal(m, b)
+}
+func (m *ReloadResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReloadResponse.Marshal(b, m, deterministic)
+}
+func (m *ReloadResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReloadResponse.Merge(m, src)
+}
+func (m *ReloadResponse) XXX_Size() int {
+	return xxx_messageInfo_ReloadResponse.Size(m)
+}
+func (m *ReloadResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReloadResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReloadResponse proto.InternalMessageInfo
+
+func (m *ReloadResponse) GetApplied() bool {
+	if m != nil {
+		return m.Applied
+	}
+	return false
+}
+
+func (m *ReloadResponse) GetErrors() []string {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+// Event is something that happened inside the daemon
+type Event struct {
+	Time                 int64             `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Type                 string            `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Service              string            `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	Message              string            `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Fields               map[string]string `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{12}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Event) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Event) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *Event) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Event) GetFields() map[string]string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+// EventsRequest selects events. If follow is false, only the
+// backlog of recent events is sent.
+type EventsRequest struct {
+	Follow               bool     `protobuf:"varint,1,opt,name=follow,proto3" json:"follow,omitempty"`
+	Service              string   `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventsRequest) Reset()         { *m = EventsRequest{} }
+func (m *EventsRequest) String() string { return proto.CompactTextString(m) }
+func (*EventsRequest) ProtoMessage()    {}
+func (*EventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{13}
+}
+
+func (m *EventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventsRequest.Unmarshal(m, b)
+}
+func (m *EventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventsRequest.Marshal(b, m, deterministic)
+}
+func (m *EventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventsRequest.Merge(m, src)
+}
+func (m *EventsRequest) XXX_Size() int {
+	return xxx_messageInfo_EventsRequest.Size(m)
+}
+func (m *EventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventsRequest proto.InternalMessageInfo
+
+func (m *EventsRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+func (m *EventsRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+// LogLevelRequest changes the log level of a component, or the
+// global level if component is empty. An empty level only queries levels.
+type LogLevelRequest struct {
+	Level                string   `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	Component            string   `protobuf:"bytes,2,opt,name=component,proto3" json:"component,omitempty"`
+	RevertAfterSecs      int64    `protobuf:"varint,3,opt,name=revertAfterSecs,proto3" json:"revertAfterSecs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogLevelRequest) Reset()         { *m = LogLevelRequest{} }
+func (m *LogLevelRequest) String() string { return proto.CompactTextString(m) }
+func (*LogLevelRequest) ProtoMessage()    {}
+func (*LogLevelRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{14}
+}
+
+func (m *LogLevelRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLevelRequest.Unmarshal(m, b)
+}
+func (m *LogLevelRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLevelRequest.Marshal(b, m, deterministic)
+}
+func (m *LogLevelRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLevelRequest.Merge(m, src)
+}
+func (m *LogLevelRequest) XXX_Size() int {
+	return xxx_messageInfo_LogLevelRequest.Size(m)
+}
+func (m *LogLevelRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLevelRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLevelRequest proto.InternalMessageInfo
+
+func (m *LogLevelRequest) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+func (m *LogLevelRequest) GetComponent() string {
+	if m != nil {
+		return m.Component
+	}
+	return ""
+}
+
+func (m *LogLevelRequest) GetRevertAfterSecs() int64 {
+	if m != nil {
+		return m.RevertAfterSecs
+	}
+	return 0
+}
+
+type ComponentLogLevel struct {
+	Component            string   `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	Level                string   `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	Override             bool     `protobuf:"varint,3,opt,name=override,proto3" json:"override,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ComponentLogLevel) Reset()         { *m = ComponentLogLevel{} }
+func (m *ComponentLogLevel) String() string { return proto.CompactTextString(m) }
+func (*ComponentLogLevel) ProtoMessage()    {}
+func (*ComponentLogLevel) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{15}
+}
+
+func (m *ComponentLogLevel) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ComponentLogLevel.Unmarshal(m, b)
+}
+func (m *ComponentLogLevel) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ComponentLogLevel.Marshal(b, m, deterministic)
+}
+func (m *ComponentLogLevel) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ComponentLogLevel.Merge(m, src)
+}
+func (m *ComponentLogLevel) XXX_Size() int {
+	return xxx_messageInfo_ComponentLogLevel.Size(m)
+}
+func (m *ComponentLogLevel) XXX_DiscardUnknown() {
+	xxx_messageInfo_ComponentLogLevel.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ComponentLogLevel proto.InternalMessageInfo
+
+func (m *ComponentLogLevel) GetComponent() string {
+	if m != nil {
+		return m.Component
+	}
+	return ""
+}
+
+func (m *ComponentLogLevel) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+func (m *ComponentLogLevel) GetOverride() bool {
+	if m != nil {
+		return m.Override
+	}
+	return false
+}
+
+type LogLevelResponse struct {
+	Levels               []*ComponentLogLevel `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *LogLevelResponse) Reset()         { *m = LogLevelResponse{} }
+func (m *LogLevelResponse) String() string { return proto.CompactTextString(m) }
+func (*LogLevelResponse) ProtoMessage()    {}
+func (*LogLevelResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{16}
+}
+
+func (m *LogLevelResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogLevelResponse.Unmarshal(m, b)
+}
+func (m *LogLevelResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogLevelResponse.Marshal(b, m, deterministic)
+}
+func (m *LogLevelResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogLevelResponse.Merge(m, src)
+}
+func (m *LogLevelResponse) XXX_Size() int {
+	return xxx_messageInfo_LogLevelResponse.Size(m)
+}
+func (m *LogLevelResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogLevelResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogLevelResponse proto.InternalMessageInfo
+
+func (m *LogLevelResponse) GetLevels() []*ComponentLogLevel {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+// BackendRequest selects a backend of a service
+type BackendRequest struct {
+	Service              string   `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	RemoveAfterSecs      int64    `protobuf:"varint,3,opt,name=removeAfterSecs,proto3" json:"removeAfterSecs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackendRequest) Reset()         { *m = BackendRequest{} }
+func (m *BackendRequest) String() string { return proto.CompactTextString(m) }
+func (*BackendRequest) ProtoMessage()    {}
+func (*BackendRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{17}
+}
+
+func (m *BackendRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackendRequest.Unmarshal(m, b)
+}
+func (m *BackendRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackendRequest.Marshal(b, m, deterministic)
+}
+func (m *BackendRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackendRequest.Merge(m, src)
+}
+func (m *BackendRequest) XXX_Size() int {
+	return xxx_messageInfo_BackendRequest.Size(m)
+}
+func (m *BackendRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackendRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackendRequest proto.InternalMessageInfo
+
+func (m *BackendRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *BackendRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *BackendRequest) GetRemoveAfterSecs() int64 {
+	if m != nil {
+		return m.RemoveAfterSecs
+	}
+	return 0
+}
+
+// DiffRequest contains a candidate configuration file
+type DiffRequest struct {
+	Config               []byte   `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DiffRequest) Reset()         { *m = DiffRequest{} }
+func (m *DiffRequest) String() string { return proto.CompactTextString(m) }
+func (*DiffRequest) ProtoMessage()    {}
+func (*DiffRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{18}
+}
+
+func (m *DiffRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiffRequest.Unmarshal(m, b)
+}
+func (m *DiffRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiffRequest.Marshal(b, m, deterministic)
+}
+func (m *DiffRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiffRequest.Merge(m, src)
+}
+func (m *DiffRequest) XXX_Size() int {
+	return xxx_messageInfo_DiffRequest.Size(m)
+}
+func (m *DiffRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiffRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiffRequest proto.InternalMessageInfo
+
+func (m *DiffRequest) GetConfig() []byte {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+// ModelChange is a single difference between the applied
+// and the candidate ipvsctl model
+type ModelChange struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Service              string   `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	ServiceName          string   `protobuf:"bytes,3,opt,name=serviceName,proto3" json:"serviceName,omitempty"`
+	Destination          string   `protobuf:"bytes,4,opt,name=destination,proto3" json:"destination,omitempty"`
+	Field                string   `protobuf:"bytes,5,opt,name=field,proto3" json:"field,omitempty"`
+	Old                  string   `protobuf:"bytes,6,opt,name=old,proto3" json:"old,omitempty"`
+	New                  string   `protobuf:"bytes,7,opt,name=new,proto3" json:"new,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelChange) Reset()         { *m = ModelChange{} }
+func (m *ModelChange) String() string { return proto.CompactTextString(m) }
+func (*ModelChange) ProtoMessage()    {}
+func (*ModelChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{19}
+}
+
+func (m *ModelChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelChange.Unmarshal(m, b)
+}
+func (m *ModelChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelChange.Marshal(b, m, deterministic)
+}
+func (m *ModelChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelChange.Merge(m, src)
+}
+func (m *ModelChange) XXX_Size() int {
+	return xxx_messageInfo_ModelChange.Size(m)
+}
+func (m *ModelChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelChange proto.InternalMessageInfo
+
+func (m *ModelChange) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *ModelChange) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *ModelChange) GetServiceName() string {
+	if m != nil {
+		return m.ServiceName
+	}
+	return ""
+}
+
+func (m *ModelChange) GetDestination() string {
+	if m != nil {
+		return m.Destination
+	}
+	return ""
+}
+
+func (m *ModelChange) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *ModelChange) GetOld() string {
+	if m != nil {
+		return m.Old
+	}
+	return ""
+}
+
+func (m *ModelChange) GetNew() string {
+	if m != nil {
+		return m.New
+	}
+	return ""
+}
+
+type DiffResponse struct {
+	Changes              []*ModelChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Errors               []string       `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	Warnings             []string       `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *DiffResponse) Reset()         { *m = DiffResponse{} }
+func (m *DiffResponse) String() string { return proto.CompactTextString(m) }
+func (*DiffResponse) ProtoMessage()    {}
+func (*DiffResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81159ba547ea6f30, []int{20}
+}
+
+func (m *DiffResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiffResponse.Unmarshal(m, b)
+}
+func (m *DiffResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiffResponse.Marshal(b, m, deterministic)
+}
+func (m *DiffResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiffResponse.Merge(m, src)
+}
+func (m *DiffResponse) XXX_Size() int {
+	return xxx_messageInfo_DiffResponse.Size(m)
+}
+func (m *DiffResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiffResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiffResponse proto.InternalMessageInfo
+
+func (m *DiffResponse) GetChanges() []*ModelChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func (m *DiffResponse) GetErrors() []string {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *DiffResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "localinterface.Empty")
+	proto.RegisterType((*ServiceWorkerStatus)(nil), "localinterface.ServiceWorkerStatus")
+	proto.RegisterType((*PublisherStatus)(nil), "localinterface.PublisherStatus")
+	proto.RegisterMapType((map[string]string)(nil), "localinterface.PublisherStatus.MatchLabelsEntry")
+	proto.RegisterType((*ApplyStatus)(nil), "localinterface.ApplyStatus")
+	proto.RegisterType((*BackendOverrideStatus)(nil), "localinterface.BackendOverrideStatus")
+	proto.RegisterType((*StatusResponse)(nil), "localinterface.StatusResponse")
+	proto.RegisterType((*Backend)(nil), "localinterface.Backend")
+	proto.RegisterMapType((map[string]string)(nil), "localinterface.Backend.AdditionalInfoEntry")
+	proto.RegisterType((*ServiceInfo)(nil), "localinterface.ServiceInfo")
+	proto.RegisterType((*ServiceList)(nil), "localinterface.ServiceList")
+	proto.RegisterType((*ServiceRequest)(nil), "localinterface.ServiceRequest")
+	proto.RegisterType((*AddServiceRequest)(nil), "localinterface.AddServiceRequest")
+	proto.RegisterType((*ReloadResponse)(nil), "localinterface.ReloadResponse")
+	proto.RegisterType((*Event)(nil), "localinterface.Event")
+	proto.RegisterMapType((map[string]string)(nil), "localinterface.Event.FieldsEntry")
+	proto.RegisterType((*EventsRequest)(nil), "localinterface.EventsRequest")
+	proto.RegisterType((*LogLevelRequest)(nil), "localinterface.LogLevelRequest")
+	proto.RegisterType((*ComponentLogLevel)(nil), "localinterface.ComponentLogLevel")
+	proto.RegisterType((*LogLevelResponse)(nil), "localinterface.LogLevelResponse")
+	proto.RegisterType((*BackendRequest)(nil), "localinterface.BackendRequest")
+	proto.RegisterType((*DiffRequest)(nil), "localinterface.DiffRequest")
+	proto.RegisterType((*ModelChange)(nil), "localinterface.ModelChange")
+	proto.RegisterType((*DiffResponse)(nil), "localinterface.DiffResponse")
+}
+
+func init() { proto.RegisterFile("cli.proto", fileDescriptor_81159ba547ea6f30) }
+
+var fileDescriptor_81159ba547ea6f30 = []byte{
+	// 1444 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0xdb, 0x6e, 0xdb, 0x46,
+	0x13, 0x06, 0x25, 0x4b, 0x96, 0x46, 0xb6, 0x93, 0x6c, 0x0e, 0x3f, 0xa1, 0x3f, 0xbf, 0x7f, 0x97,
+	0x4d, 0x0a, 0x03, 0x45, 0x8d, 0x20, 0xe9, 0xc9, 0xbd, 0x68, 0xea, 0x38, 0x76, 0x10, 0xc0, 0x6e,
+	0x8d, 0x75, 0x8a, 0x5e, 0xd3, 0xe4, 0x4a, 0x26, 0x4c, 0xed, 0xb2, 0xdc, 0xa5, 0x5d, 0x3f, 0x55,
+	0xaf, 0x73, 0x99, 0x07, 0xe8, 0x03, 0xf4, 0x11, 0xfa, 0x02, 0x45, 0x2f, 0x8b, 0xd9, 0x83, 0x48,
+	0x51, 0x64, 0x1a, 0xa3, 0x77, 0x3b, 0xa3, 0x9d, 0xe1, 0x1c, 0xbe, 0xf9, 0x66, 0x6d, 0x18, 0x46,
+	0x69, 0xb2, 0x93, 0xe5, 0x42, 0x09, 0xb2, 0x91, 0x8a, 0x28, 0x4c, 0x13, 0xae, 0x58, 0x3e, 0x09,
+	0x23, 0x16, 0xac, 0x42, 0xef, 0x60, 0x96, 0xa9, 0xeb, 0xe0, 0x0f, 0x0f, 0xee, 0x9e, 0xb2, 0xfc,
+	0x32, 0x89, 0xd8, 0x4f, 0x22, 0xbf, 0x60, 0xf9, 0xa9, 0x0a, 0x55, 0x21, 0x09, 0x81, 0x15, 0x1e,
+	0xce, 0x98, 0xef, 0x6d, 0x79, 0xdb, 0x43, 0xaa, 0xcf, 0xa8, 0x53, 0xd7, 0x19, 0xf3, 0x3b, 0x46,
+	0x87, 0x67, 0xe2, 0xc3, 0x6a, 0x18, 0xc7, 0x39, 0x93, 0xd2, 0xef, 0x6a, 0xb5, 0x13, 0xc9, 0x16,
+	0x8c, 0x78, 0x31, 0x7b, 0x11, 0x46, 0x17, 0x8c, 0xc7, 0xd2, 0x5f, 0xd9, 0xf2, 0xb6, 0x7b, 0xb4,
+	0xaa, 0x22, 0x9b, 0x00, 0x69, 0x28, 0xd5, 0x8f, 0x59, 0x1c, 0x2a, 0xe6, 0xf7, 0xb6, 0xbc, 0xed,
+	0x2e, 0xad, 0x68, 0xc8, 0x43, 0x18, 0xa2, 0x74, 0x90, 0xe7, 0x22, 0xf7, 0xfb, 0xda, 0x7b, 0xa9,
+	0xc0, 0x2f, 0xe7, 0x05, 0x57, 0xc9, 0x8c, 0xf9, 0xab, 0x5b, 0xde, 0xf6, 0x80, 0x3a, 0x91, 0x3c,
+	0x80, 0xbe, 0x14, 0x45, 0x1e, 0x31, 0x7f, 0xa0, 0x8d, 0xac, 0x14, 0xfc, 0xe6, 0xc1, 0xad, 0x93,
+	0xe2, 0x2c, 0x4d, 0xe4, 0xf9, 0x8d, 0xf3, 0xa4, 0x30, 0x9a, 0x85, 0x2a, 0x3a, 0x3f, 0x0a, 0xcf,
+	0x58, 0x8a, 0xb9, 0x76, 0xb7, 0x47, 0x4f, 0x9f, 0xec, 0x2c, 0x96, 0x75, 0xa7, 0xe6, 0x7d, 0xe7,
+	0xb8, 0x34, 0x39, 0xe0, 0x2a, 0xbf, 0xa6, 0x55, 0x27, 0xe3, 0x6f, 0xe1, 0x76, 0xfd, 0x02, 0xb9,
+	0x0d, 0xdd, 0x0b, 0x76, 0x6d, 0xc3, 0xc1, 0x23, 0xb9, 0x07, 0xbd, 0xcb, 0x30, 0x2d, 0x5c, 0x38,
+	0x46, 0xf8, 0xa6, 0xf3, 0xb5, 0x17, 0xbc, 0xf3, 0x60, 0xb4, 0x97, 0x65, 0xe9, 0x75, 0x99, 0x8b,
+	0x2e, 0x87, 0xa7, 0x2b, 0xa9, 0xcf, 0x58, 0x25, 0x59, 0x44, 0x11, 0xf6, 0xa7, 0x63, 0xaa, 0x64,
+	0x45, 0xf4, 0xcb, 0x74, 0x65, 0x4d, 0xdf, 0x8c, 0x40, 0x1e, 0xc1, 0x3a, 0xfb, 0x85, 0x45, 0x85,
+	0x4a, 0x04, 0x7f, 0x83, 0x45, 0x58, 0xd1, 0xbf, 0x2e, 0x2a, 0xc9, 0x27, 0xb0, 0x11, 0x17, 0x79,
+	0x88, 0xf2, 0x71, 0x92, 0xa6, 0x89, 0xb4, 0xdd, 0xab, 0x69, 0x2d, 0x06, 0x2c, 0xbe, 0xa4, 0xdf,
+	0x9f, 0x63, 0xc0, 0xa9, 0x82, 0x5f, 0x3d, 0xb8, 0x6f, 0x01, 0xf1, 0xc3, 0x25, 0xcb, 0xf3, 0x24,
+	0x66, 0x36, 0x1b, 0x8c, 0xdc, 0xdc, 0xb2, 0xd5, 0x70, 0x62, 0x15, 0x73, 0x9d, 0x45, 0xcc, 0x3d,
+	0x80, 0xfe, 0x15, 0x4b, 0xa6, 0xe7, 0x4a, 0x27, 0xd5, 0xa3, 0x56, 0xc2, 0x5c, 0x65, 0xc2, 0x23,
+	0x93, 0x4d, 0x97, 0x1a, 0x81, 0x8c, 0x61, 0x90, 0xb3, 0x99, 0xb8, 0x64, 0x7b, 0xca, 0xc6, 0x3f,
+	0x97, 0x35, 0xba, 0xf4, 0x39, 0xf6, 0xfb, 0x16, 0x5d, 0x46, 0x0c, 0xfe, 0xea, 0xc2, 0x86, 0x09,
+	0x91, 0x32, 0x99, 0x09, 0x2e, 0x35, 0x50, 0xa5, 0x0a, 0x73, 0xf5, 0xa6, 0xac, 0x7e, 0xa9, 0x40,
+	0x98, 0x17, 0x19, 0x36, 0xe3, 0x94, 0x45, 0x26, 0xe2, 0x2e, 0xad, 0x68, 0xf0, 0xf7, 0x48, 0xf0,
+	0x49, 0x32, 0x3d, 0x4c, 0x52, 0x66, 0xbb, 0x51, 0xd1, 0x60, 0x4b, 0x8c, 0x74, 0x2c, 0x62, 0xfd,
+	0x05, 0x93, 0xc4, 0xa2, 0x92, 0x3c, 0x87, 0x81, 0x74, 0x75, 0xee, 0x69, 0x74, 0x7e, 0x5c, 0x47,
+	0x67, 0xc3, 0x9c, 0xd3, 0xb9, 0x11, 0x79, 0x0e, 0x90, 0x39, 0xf8, 0x62, 0xab, 0xd0, 0xc5, 0xff,
+	0xff, 0x01, 0xe0, 0xb4, 0x62, 0x42, 0x76, 0xcd, 0xb8, 0x6a, 0x44, 0xea, 0x91, 0x1c, 0x3d, 0xfd,
+	0x6f, 0xdd, 0xbe, 0x02, 0x57, 0x5a, 0xde, 0x26, 0xfb, 0x30, 0x14, 0xb6, 0xfb, 0xd2, 0x1f, 0xe8,
+	0x4f, 0x3f, 0xae, 0x9b, 0x36, 0xa2, 0x84, 0x96, 0x76, 0xd8, 0xfc, 0x2c, 0x2c, 0x24, 0x8b, 0xfd,
+	0xa1, 0xee, 0x98, 0x95, 0x10, 0x84, 0xe6, 0x74, 0xaa, 0x21, 0x00, 0xba, 0x7a, 0x55, 0x15, 0xc2,
+	0x39, 0x63, 0x3c, 0x4e, 0xf8, 0x74, 0xff, 0x3c, 0xe4, 0x53, 0x26, 0xfd, 0x91, 0xf6, 0x50, 0xd3,
+	0x06, 0x6f, 0x3b, 0xb0, 0x6a, 0xc3, 0xa8, 0x82, 0xd0, 0x6b, 0x03, 0x61, 0x67, 0x01, 0x84, 0xa7,
+	0xb0, 0x11, 0xc6, 0x71, 0x82, 0xe3, 0x11, 0xa6, 0xaf, 0xf9, 0x44, 0x58, 0x16, 0xf9, 0xb4, 0x25,
+	0xd3, 0x9d, 0xbd, 0x85, 0xdb, 0x86, 0x40, 0x6a, 0x2e, 0x34, 0xb2, 0x55, 0xa8, 0x0c, 0x28, 0x86,
+	0xd4, 0x08, 0x98, 0x50, 0x91, 0x65, 0x2c, 0x7f, 0x73, 0x9e, 0x33, 0x79, 0x2e, 0xd2, 0x58, 0xe3,
+	0xbb, 0x47, 0x6b, 0x5a, 0xbc, 0x97, 0x8a, 0xab, 0xea, 0x3d, 0x33, 0xa2, 0x35, 0xed, 0x78, 0x0f,
+	0xee, 0x36, 0x04, 0x73, 0x23, 0xb2, 0xfa, 0xb3, 0x03, 0x23, 0x0b, 0x40, 0x1d, 0x78, 0x13, 0xf1,
+	0xb6, 0x0f, 0xb6, 0xa3, 0xe4, 0x6e, 0x85, 0x92, 0x71, 0xea, 0xa2, 0x73, 0x16, 0x17, 0x29, 0xcb,
+	0x6d, 0xfa, 0xa5, 0x02, 0x7d, 0x4d, 0x44, 0x7e, 0x15, 0xe6, 0x26, 0xf7, 0x21, 0x75, 0x22, 0x79,
+	0x06, 0x83, 0x33, 0xb7, 0x95, 0x0c, 0xcc, 0xff, 0xd3, 0xd2, 0x01, 0x3a, 0xbf, 0x78, 0xf3, 0x6d,
+	0x43, 0x76, 0x80, 0x64, 0x2c, 0x97, 0x89, 0x54, 0x8c, 0x47, 0x0c, 0x67, 0x54, 0x14, 0x4a, 0x43,
+	0xb3, 0x47, 0x1b, 0x7e, 0xc1, 0x2f, 0x70, 0xa6, 0x66, 0xa1, 0xbc, 0xd0, 0x10, 0x1d, 0x52, 0x27,
+	0x22, 0x41, 0xe8, 0xbc, 0x0e, 0xd3, 0x70, 0x8a, 0xd0, 0xec, 0x22, 0x41, 0x94, 0x1a, 0x2c, 0x84,
+	0xe0, 0xec, 0x04, 0x43, 0x55, 0xfe, 0x9a, 0x8e, 0xae, 0x54, 0x04, 0x87, 0xf3, 0xba, 0x1f, 0x25,
+	0x52, 0x91, 0xaf, 0x2a, 0x3c, 0xe1, 0x6d, 0x75, 0x9b, 0x86, 0xb4, 0xd2, 0xa6, 0x92, 0x1f, 0x82,
+	0x47, 0xb0, 0x61, 0x7f, 0xa0, 0xec, 0xe7, 0x82, 0x49, 0xd5, 0xd4, 0xc2, 0xe0, 0x33, 0xb8, 0xb3,
+	0x17, 0xc7, 0xb5, 0x8b, 0x35, 0x2a, 0x5f, 0x9b, 0x53, 0x79, 0xf0, 0x02, 0x36, 0x28, 0x4b, 0x45,
+	0x18, 0xcf, 0xb9, 0x14, 0x31, 0x90, 0x65, 0x69, 0xc2, 0x62, 0x7d, 0x77, 0x40, 0x9d, 0x88, 0x85,
+	0xd6, 0x3b, 0x0a, 0xc1, 0x81, 0x25, 0xb0, 0x52, 0xf0, 0xbb, 0x07, 0xbd, 0x83, 0x4b, 0xc6, 0x55,
+	0xe3, 0x02, 0x6c, 0x79, 0xb4, 0xb8, 0x78, 0xba, 0x4b, 0xab, 0x65, 0xc6, 0xa4, 0x0c, 0xa7, 0x6e,
+	0xa0, 0x9c, 0x48, 0x76, 0xa1, 0x3f, 0x49, 0x58, 0x1a, 0x3b, 0x76, 0xfd, 0xa8, 0x5e, 0x35, 0x1d,
+	0xc2, 0xce, 0xa1, 0xbe, 0x63, 0x66, 0xd5, 0x1a, 0x8c, 0x77, 0x61, 0x54, 0x51, 0xdf, 0x68, 0x6a,
+	0xf6, 0x60, 0x5d, 0xfb, 0x95, 0xae, 0x94, 0x0f, 0xa0, 0x3f, 0x11, 0x69, 0x2a, 0xae, 0x6c, 0x75,
+	0xac, 0x54, 0x4d, 0xa9, 0xb3, 0x90, 0x52, 0x20, 0xe0, 0xd6, 0x91, 0x98, 0x1e, 0xb1, 0x4b, 0x96,
+	0x3a, 0x27, 0xf7, 0xa0, 0x97, 0xa2, 0x6c, 0x63, 0x30, 0x02, 0xc2, 0x28, 0x12, 0xb3, 0x4c, 0x70,
+	0xc6, 0x95, 0x75, 0x52, 0x2a, 0xc8, 0x36, 0xdc, 0xca, 0xd9, 0x25, 0xcb, 0xd5, 0xde, 0x44, 0xb1,
+	0x5c, 0xaf, 0xb2, 0xae, 0x2e, 0x73, 0x5d, 0x1d, 0x44, 0x70, 0x67, 0xdf, 0x99, 0xb9, 0x2f, 0x2f,
+	0x3a, 0xf7, 0xea, 0xce, 0xe7, 0x01, 0x75, 0xaa, 0x01, 0x8d, 0x61, 0xe0, 0xd8, 0x5d, 0x7f, 0x6b,
+	0x40, 0xe7, 0x72, 0x70, 0x0c, 0xb7, 0xcb, 0xac, 0x2c, 0x74, 0x76, 0xa1, 0xaf, 0x0d, 0x1d, 0xb0,
+	0x97, 0x5a, 0xb4, 0x14, 0x16, 0xb5, 0x06, 0x01, 0x87, 0x0d, 0x37, 0xf3, 0xcd, 0x98, 0xfd, 0xa0,
+	0xe7, 0x87, 0xae, 0x91, 0x7e, 0x40, 0x2c, 0xd7, 0x68, 0x41, 0x1d, 0x3c, 0x86, 0xd1, 0xcb, 0x64,
+	0x32, 0xa9, 0x74, 0xd5, 0x6c, 0x73, 0x3b, 0x1f, 0x56, 0x0a, 0xde, 0x7a, 0x30, 0x3a, 0x16, 0x31,
+	0x4b, 0xcd, 0x06, 0x42, 0x30, 0x5f, 0x24, 0x3c, 0x76, 0x13, 0x87, 0xe7, 0xf6, 0xce, 0xe3, 0xe2,
+	0xb3, 0xc7, 0xef, 0x71, 0x4c, 0x0d, 0xd4, 0xab, 0x2a, 0xbc, 0x11, 0x33, 0xa9, 0x12, 0xae, 0x1f,
+	0x6d, 0x16, 0xf2, 0x55, 0x15, 0x76, 0x46, 0xa3, 0xd8, 0x92, 0xa8, 0x11, 0x10, 0xc2, 0x6e, 0x59,
+	0x0c, 0x69, 0x57, 0x18, 0x0d, 0x67, 0x57, 0x9a, 0x1b, 0x87, 0x14, 0x8f, 0xc1, 0x35, 0xac, 0x99,
+	0x14, 0x6d, 0x77, 0xbe, 0x80, 0xd5, 0xc8, 0x6e, 0xd7, 0x16, 0xde, 0xa9, 0x64, 0x4a, 0xdd, 0xdd,
+	0xb6, 0xa9, 0x47, 0x70, 0x5c, 0x85, 0x39, 0x4f, 0xf8, 0xd4, 0xbc, 0xc6, 0x87, 0x74, 0x2e, 0x3f,
+	0x7d, 0xb7, 0x0a, 0xeb, 0x2f, 0x43, 0x36, 0x13, 0xdc, 0x12, 0x11, 0xf9, 0x1c, 0x56, 0x4e, 0x95,
+	0xc8, 0xc8, 0xfd, 0xa5, 0xa9, 0xc5, 0xbf, 0x82, 0xc6, 0xcd, 0x6a, 0xf2, 0x1c, 0xfa, 0xf6, 0x31,
+	0xda, 0x62, 0xb7, 0xb9, 0x44, 0x9d, 0x8b, 0x0f, 0xc3, 0x17, 0xb0, 0x86, 0xa4, 0xeb, 0x5e, 0xbb,
+	0x6d, 0x6e, 0xda, 0x18, 0x18, 0x6d, 0xc9, 0x6b, 0x80, 0x57, 0xcc, 0xb9, 0x20, 0x9b, 0x2d, 0x57,
+	0x2d, 0x92, 0xc6, 0xef, 0x23, 0x73, 0xcc, 0xc7, 0xb0, 0xed, 0x07, 0xe7, 0x53, 0x23, 0xe7, 0xef,
+	0xa0, 0x6f, 0xe8, 0x88, 0xfc, 0xaf, 0x91, 0xfe, 0x1c, 0x4d, 0x8d, 0xef, 0x37, 0xfe, 0xfc, 0xc4,
+	0x23, 0x27, 0xb8, 0x8d, 0x4a, 0x5a, 0x58, 0x7a, 0x60, 0xd6, 0xa8, 0x6a, 0xbc, 0xd5, 0x7e, 0xc1,
+	0xc6, 0x74, 0x00, 0x6b, 0x2f, 0xf3, 0x30, 0xe1, 0xee, 0x61, 0xb6, 0xd9, 0xb6, 0xcc, 0x5b, 0x43,
+	0xd3, 0xbd, 0x3e, 0x84, 0xf5, 0x03, 0x1e, 0x9e, 0xa5, 0xec, 0x5f, 0xfa, 0x79, 0x05, 0x80, 0xb0,
+	0xdf, 0xd7, 0x03, 0x4c, 0x96, 0xda, 0x51, 0x99, 0xfa, 0xf1, 0xc3, 0xe6, 0x1f, 0xe7, 0xf3, 0xd2,
+	0x3b, 0xc1, 0x37, 0xea, 0x0d, 0x31, 0xfb, 0x25, 0xf6, 0x58, 0x16, 0xb3, 0x9b, 0xda, 0x1d, 0x02,
+	0x94, 0x8b, 0x9b, 0x2c, 0x51, 0xe7, 0xd2, 0x52, 0x7f, 0x4f, 0x1d, 0xa9, 0x26, 0xbb, 0x0f, 0x45,
+	0x6c, 0xb3, 0x9f, 0xb3, 0xbe, 0xfe, 0xc7, 0xc5, 0xb3, 0xbf, 0x07, 0x00, 0x3c, 0xb6, 0xe7, 0x6f,
+	0xc5, 0x10, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DaemonServiceClient is the client API for DaemonService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DaemonServiceClient interface {
+	Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+	ListServices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServiceList, error)
+	GetService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*ServiceInfo, error)
+	Reload(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReloadResponse, error)
+	Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (DaemonService_EventsClient, error)
+	SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
+	DrainBackend(ctx context.Context, in *BackendRequest, opts ...grpc.CallOption) (*Empty, error)
+	EnableBackend(ctx context.Context, in *BackendRequest, opts ...grpc.CallOption) (*Empty, error)
+	DiffConfig(ctx context.Context, in *DiffRequest, opts ...grpc.CallOption) (*DiffResponse, error)
+	Pause(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Resume(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	AddService(ctx context.Context, in *AddServiceRequest, opts ...grpc.CallOption) (*Empty, error)
+	RemoveService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type daemonServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDaemonServiceClient(cc *grpc.ClientConn) DaemonServiceClient {
+	return &daemonServiceClient{cc}
+}
+
+func (c *daemonServiceClient) Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListServices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServiceList, error) {
+	out := new(ServiceList)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/ListServices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) GetService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*ServiceInfo, error) {
+	out := new(ServiceInfo)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/GetService", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) Reload(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReloadResponse, error) {
+	out := new(ReloadResponse)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/Reload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (DaemonService_EventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DaemonService_serviceDesc.Streams[0], "/localinterface.DaemonService/Events", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &daemonServiceEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DaemonService_EventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type daemonServiceEventsClient struct {
+	grpc.ClientStream
 }
 
-func init() { proto.RegisterFile("cli.proto", fileDescriptor_81159ba547ea6f30) }
+func (x *daemonServiceEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
 
-var fileDescriptor_81159ba547ea6f30 = []byte{
-	// 101 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4c, 0xce, 0xc9, 0xd4,
-	0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0xcb, 0xc9, 0x4f, 0x4e, 0xcc, 0xc9, 0xcc, 0x2b, 0x49,
-	0x2d, 0x4a, 0x4b, 0x4c, 0x4e, 0x55, 0x62, 0xe7, 0x62, 0x75, 0xcd, 0x2d, 0x28, 0xa9, 0x34, 0x72,
-	0xe5, 0xe2, 0x75, 0x49, 0x4c, 0xcd, 0xcd, 0xcf, 0x0b, 0x4e, 0x2d, 0x2a, 0xcb, 0x4c, 0x4e, 0x15,
-	0x32, 0xe1, 0x62, 0x09, 0x2e, 0xc9, 0x2f, 0x10, 0x12, 0xd5, 0x43, 0xd5, 0xa2, 0x07, 0x56, 0x2f,
-	0x85, 0x5d, 0x38, 0x89, 0x0d, 0x6c, 0x8d, 0x31, 0x20, 0x00, 0x00, 0xff, 0xff, 0x3f, 0xcb, 0x23,
-	0xca, 0x73, 0x00, 0x00, 0x00,
+func (c *daemonServiceClient) SetLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error) {
+	out := new(LogLevelResponse)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (c *daemonServiceClient) DrainBackend(ctx context.Context, in *BackendRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/DrainBackend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (c *daemonServiceClient) EnableBackend(ctx context.Context, in *BackendRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/EnableBackend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// DaemonServiceClient is the client API for DaemonService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DaemonServiceClient interface {
-	Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+func (c *daemonServiceClient) DiffConfig(ctx context.Context, in *DiffRequest, opts ...grpc.CallOption) (*DiffResponse, error) {
+	out := new(DiffResponse)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/DiffConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type daemonServiceClient struct {
-	cc *grpc.ClientConn
+func (c *daemonServiceClient) Pause(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func NewDaemonServiceClient(cc *grpc.ClientConn) DaemonServiceClient {
-	return &daemonServiceClient{cc}
+func (c *daemonServiceClient) Resume(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *daemonServiceClient) Stop(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+func (c *daemonServiceClient) AddService(ctx context.Context, in *AddServiceRequest, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
-	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/Stop", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/AddService", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) RemoveService(ctx context.Context, in *ServiceRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/localinterface.DaemonService/RemoveService", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +1685,19 @@ func (c *daemonServiceClient) Stop(ctx context.Context, in *Empty, opts ...grpc.
 // DaemonServiceServer is the server API for DaemonService service.
 type DaemonServiceServer interface {
 	Stop(context.Context, *Empty) (*Empty, error)
+	Status(context.Context, *Empty) (*StatusResponse, error)
+	ListServices(context.Context, *Empty) (*ServiceList, error)
+	GetService(context.Context, *ServiceRequest) (*ServiceInfo, error)
+	Reload(context.Context, *Empty) (*ReloadResponse, error)
+	Events(*EventsRequest, DaemonService_EventsServer) error
+	SetLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
+	DrainBackend(context.Context, *BackendRequest) (*Empty, error)
+	EnableBackend(context.Context, *BackendRequest) (*Empty, error)
+	DiffConfig(context.Context, *DiffRequest) (*DiffResponse, error)
+	Pause(context.Context, *Empty) (*Empty, error)
+	Resume(context.Context, *Empty) (*Empty, error)
+	AddService(context.Context, *AddServiceRequest) (*Empty, error)
+	RemoveService(context.Context, *ServiceRequest) (*Empty, error)
 }
 
 func RegisterDaemonServiceServer(s *grpc.Server, srv DaemonServiceServer) {
@@ -130,6 +1722,243 @@ func _DaemonService_Stop_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).Status(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/ListServices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListServices(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_GetService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).GetService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/GetService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).GetService(ctx, req.(*ServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_Reload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).Reload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/Reload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).Reload(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServiceServer).Events(m, &daemonServiceEventsServer{stream})
+}
+
+type DaemonService_EventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type daemonServiceEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *daemonServiceEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DaemonService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).SetLogLevel(ctx, req.(*LogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_DrainBackend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).DrainBackend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/DrainBackend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).DrainBackend(ctx, req.(*BackendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_EnableBackend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).EnableBackend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/EnableBackend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).EnableBackend(ctx, req.(*BackendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_DiffConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).DiffConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/DiffConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).DiffConfig(ctx, req.(*DiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/Pause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).Pause(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/Resume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).Resume(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_AddService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).AddService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/AddService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).AddService(ctx, req.(*AddServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_RemoveService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RemoveService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/localinterface.DaemonService/RemoveService",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RemoveService(ctx, req.(*ServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _DaemonService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "localinterface.DaemonService",
 	HandlerType: (*DaemonServiceServer)(nil),
@@ -138,7 +1967,61 @@ var _DaemonService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Stop",
 			Handler:    _DaemonService_Stop_Handler,
 		},
+		{
+			MethodName: "Status",
+			Handler:    _DaemonService_Status_Handler,
+		},
+		{
+			MethodName: "ListServices",
+			Handler:    _DaemonService_ListServices_Handler,
+		},
+		{
+			MethodName: "GetService",
+			Handler:    _DaemonService_GetService_Handler,
+		},
+		{
+			MethodName: "Reload",
+			Handler:    _DaemonService_Reload_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _DaemonService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "DrainBackend",
+			Handler:    _DaemonService_DrainBackend_Handler,
+		},
+		{
+			MethodName: "EnableBackend",
+			Handler:    _DaemonService_EnableBackend_Handler,
+		},
+		{
+			MethodName: "DiffConfig",
+			Handler:    _DaemonService_DiffConfig_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _DaemonService_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _DaemonService_Resume_Handler,
+		},
+		{
+			MethodName: "AddService",
+			Handler:    _DaemonService_AddService_Handler,
+		},
+		{
+			MethodName: "RemoveService",
+			Handler:    _DaemonService_RemoveService_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Events",
+			Handler:       _DaemonService_Events_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "cli.proto",
 }
@@ -1,14 +1,24 @@
 package logging
 
 import (
+	"fmt"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 var (
 	once sync.Once
+
+	// per-component loggers and their level overrides. Components
+	// without an override follow the level of the standard logger.
+	mu           sync.Mutex
+	components   = make(map[string]*log.Logger)
+	overrides    = make(map[string]log.Level)
+	revertTimers = make(map[string]*time.Timer)
 )
 
 // InitLogging creates a new logger based on verbosity and settings
@@ -30,6 +40,149 @@ func InitLogging(trace, debug, verbose bool) {
 
 		// file
 
-		log.SetFormatter(&log.TextFormatter{})
+		log.SetFormatter(redactingFormatter{&log.TextFormatter{}})
+
+		mu.Lock()
+		defer mu.Unlock()
+		applyLevels()
 	})
 }
+
+// stdWriter and stdFormatter forward to the standard logger, so
+// component loggers follow output and format changes made there.
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	return log.StandardLogger().Out.Write(p)
+}
+
+type stdFormatter struct{}
+
+func (stdFormatter) Format(e *log.Entry) ([]byte, error) {
+	return log.StandardLogger().Formatter.Format(e)
+}
+
+// Component returns the logger of a named component, e.g. "ipvsapplier"
+// or "plugin:dockerFrontProxy". It writes to the same destinations as the
+// standard logger, but its level can be changed individually using SetLevel.
+func Component(name string) *log.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	l, ex := components[name]
+	if !ex {
+		l = &log.Logger{
+			Out:       stdWriter{},
+			Formatter: stdFormatter{},
+			Hooks:     log.StandardLogger().Hooks,
+			Level:     log.GetLevel(),
+			ExitFunc:  os.Exit,
+		}
+		components[name] = l
+	}
+	return l
+}
+
+// applyLevels sets the level of all component loggers. Caller must hold mu
+func applyLevels() {
+	for name, l := range components {
+		if level, ex := overrides[name]; ex {
+			l.SetLevel(level)
+		} else {
+			l.SetLevel(log.GetLevel())
+		}
+	}
+}
+
+// SetLevel changes the log level of a component, or of the standard logger and all
+// components without an override if component is empty. If revertAfter is > 0,
+// the previous level is restored after that duration.
+func SetLevel(component string, level log.Level, revertAfter time.Duration) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if component != "" {
+		if _, ex := components[component]; !ex {
+			return fmt.Errorf("unknown log component: %s", component)
+		}
+	}
+
+	if t, ex := revertTimers[component]; ex {
+		t.Stop()
+		delete(revertTimers, component)
+	}
+
+	if revertAfter > 0 {
+		prev, hasPrev := overrides[component]
+		if component == "" {
+			prev, hasPrev = log.GetLevel(), true
+		}
+		revertTimers[component] = time.AfterFunc(revertAfter, func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if hasPrev {
+				setLevel(component, prev)
+			} else {
+				delete(overrides, component)
+				applyLevels()
+			}
+			delete(revertTimers, component)
+			log.WithFields(log.Fields{
+				"component": component,
+				"level":     levelOf(component),
+			}).Info("logging: Reverted log level")
+		})
+	}
+
+	setLevel(component, level)
+	return nil
+}
+
+// setLevel applies a level to the standard logger or a component. Caller must hold mu
+func setLevel(component string, level log.Level) {
+	if component == "" {
+		log.SetLevel(level)
+	} else {
+		overrides[component] = level
+	}
+	applyLevels()
+}
+
+// levelOf returns the effective level of a component. Caller must hold mu
+func levelOf(component string) log.Level {
+	if level, ex := overrides[component]; ex {
+		return level
+	}
+	return log.GetLevel()
+}
+
+// ComponentLevel is the effective log level of a component
+type ComponentLevel struct {
+	Component string
+	Level     log.Level
+	Override  bool
+}
+
+// Levels returns the effective log levels of all components, sorted by name.
+// The standard logger is reported with an empty component name.
+func Levels() []ComponentLevel {
+	mu.Lock()
+	defer mu.Unlock()
+
+	res := []ComponentLevel{{Component: "", Level: log.GetLevel()}}
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, override := overrides[name]
+		res = append(res, ComponentLevel{
+			Component: name,
+			Level:     levelOf(name),
+			Override:  override,
+		})
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RedactedValue replaces secrets in output
+const RedactedValue = "******"
+
+var redacted = []byte(RedactedValue)
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string // longest first, so that no parts of secrets remain
+)
+
+// AddSecret registers a value which is replaced in all log output
+// from now on, e.g. a password read from a secret file. Values of any
+// length are replaced, so only register values which are secrets.
+func AddSecret(s string) {
+	if s == "" {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	// the text formatter quotes values with special characters
+	quoted := strconv.Quote(s)
+	for _, v := range []string{s, quoted[1 : len(quoted)-1]} {
+		if !containsString(secrets, v) {
+			secrets = append(secrets, v)
+		}
+	}
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces all registered secrets within b, e.g.
+// before printing configuration
+func Redact(b []byte) []byte {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	for _, s := range secrets {
+		b = bytes.Replace(b, []byte(s), redacted, -1)
+	}
+	return b
+}
+
+// redactingFormatter removes secrets from the output of another formatter
+type redactingFormatter struct {
+	log.Formatter
+}
+
+func (f redactingFormatter) Format(e *log.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(e)
+	if err != nil {
+		return b, err
+	}
+	return Redact(b), nil
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRedact(t *testing.T) {
+	AddSecret("s3cr")
+	AddSecret("s3cret-token")
+	AddSecret("ab")
+	AddSecret("")
+	AddSecret("pass\"word\n")
+
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"password: s3cret-token", "password: ******"},
+		{"a s3cr b", "a ****** b"},
+		{"short values ab are redacted", "short values ****** are redacted"},
+		{"raw pass\"word\n", "raw ******"},
+		{`quoted "pass\"word\n"`, `quoted "******"`},
+		{"nothing to redact", "nothing to redact"},
+	}
+	for _, tt := range tests {
+		if res := string(Redact([]byte(tt.in))); res != tt.expected {
+			t.Errorf("got %q, expected %q", res, tt.expected)
+		}
+	}
+}
+
+func TestRedactingFormatter(t *testing.T) {
+	AddSecret("t0ken with spaces")
+
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(redactingFormatter{&log.TextFormatter{DisableTimestamp: true}})
+	logger.WithField("auth", "t0ken with spaces").Info("connecting")
+
+	if expected := "level=info msg=connecting auth=\"******\"\n"; buf.String() != expected {
+		t.Errorf("got %q, expected %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import "strings"
+
+// ServiceOptions are options of an ipvs service which can be
+// given per service, or as defaults for all services.
+type ServiceOptions struct {
+	// ipvsctl-style scheduler name, e.g. wrr
+	SchedName string `yaml:"sched,omitempty"`
+
+	// ipvsctl-style initial weight of backends
+	Weight int `yaml:"weight,omitempty"`
+
+	// ipvsctl-style type of forward: nat, direct, tunnel
+	Forward string `yaml:"forward,omitempty"`
+
+	// PersistenceTimeout in seconds makes connections of a client stick
+	// to the same backend. Services are not persistent if neither they
+	// nor their defaults set it.
+	PersistenceTimeout int `yaml:"persistenceTimeout,omitempty"`
+
+	// Netmask groups clients for persistence, e.g. 255.255.255.0,
+	// or a prefix length for IPv6
+	Netmask string `yaml:"netmask,omitempty"`
+
+	// SchedFlags are flags of the scheduler, see SchedFlags
+	SchedFlags []string `yaml:"schedFlags,omitempty"`
+
+	// OnePacket schedules each packet of an udp service separately.
+	// Defaults only apply to udp services, which can disable it by
+	// setting it to false. See IsOnePacket.
+	OnePacket *bool `yaml:"onePacket,omitempty"`
+
+	// connection thresholds of all backends, 0 for no limit. Plugins
+	// may set them per backend.
+	UpperThreshold int `yaml:"upperThreshold,omitempty"`
+	LowerThreshold int `yaml:"lowerThreshold,omitempty"`
+}
+
+// IsOnePacket returns true if one-packet scheduling is enabled
+func (o ServiceOptions) IsOnePacket() bool {
+	return o.OnePacket != nil && *o.OnePacket
+}
+
+// BuiltinDefaults are used for options which are neither set
+// by a service nor by globals.defaults
+var BuiltinDefaults = ServiceOptions{
+	SchedName: "wrr",
+	Weight:    1000,
+	Forward:   "nat",
+}
+
+// Defaults contains options for all services, and overrides
+// for services of a plugin type
+type Defaults struct {
+	ServiceOptions `yaml:",inline"`
+
+	// Types contains defaults by plugin type, e.g. dockerFrontProxy.
+	// They take precedence over the defaults for all services.
+	Types map[string]ServiceOptions `yaml:"types,omitempty"`
+}
+
+// WithDefaults returns o with all unset options taken from d
+func (o ServiceOptions) WithDefaults(d ServiceOptions) ServiceOptions {
+	if o.SchedName == "" {
+		o.SchedName = d.SchedName
+	}
+	if o.Weight == 0 {
+		o.Weight = d.Weight
+	}
+	if o.Forward == "" {
+		o.Forward = d.Forward
+	}
+	if o.PersistenceTimeout == 0 {
+		o.PersistenceTimeout = d.PersistenceTimeout
+	}
+	if o.Netmask == "" {
+		o.Netmask = d.Netmask
+	}
+	if len(o.SchedFlags) == 0 {
+		o.SchedFlags = d.SchedFlags
+	}
+	if o.OnePacket == nil {
+		o.OnePacket = d.OnePacket
+	}
+	if o.UpperThreshold == 0 {
+		o.UpperThreshold = d.UpperThreshold
+	}
+	if o.LowerThreshold == 0 {
+		o.LowerThreshold = d.LowerThreshold
+	}
+	return o
+}
+
+// ServiceDefaults returns the defaults for services of given plugin
+// type. Defaults of the type take precedence over defaults for all
+// services, which take precedence over BuiltinDefaults.
+func (g *Globals) ServiceDefaults(serviceType string) ServiceOptions {
+	if g == nil {
+		return BuiltinDefaults
+	}
+	return g.Defaults.Types[serviceType].
+		WithDefaults(g.Defaults.ServiceOptions).
+		WithDefaults(BuiltinDefaults)
+}
+
+// Effective returns a copy of the service with all unset options
+// resolved from the defaults of its globals. This is the service
+// as it is applied to ipvs.
+func (s *Service) Effective() *Service {
+	res := *s
+	res.ServiceOptions = s.ServiceOptions.WithDefaults(s.Globals.ServiceDefaults(s.Type))
+	if s.OnePacket == nil && !strings.HasPrefix(s.Address, "udp://") {
+		res.OnePacket = nil
+	}
+	return &res
+}
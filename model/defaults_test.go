@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestEffectiveOnePacket(t *testing.T) {
+	enabled, disabled := true, false
+	globals := &Globals{Defaults: Defaults{ServiceOptions: ServiceOptions{OnePacket: &enabled}}}
+
+	tests := []struct {
+		name      string
+		address   string
+		onePacket *bool
+		expected  bool
+	}{
+		{"udp from defaults", "udp://10.0.0.1:53", nil, true},
+		{"udp disabled", "udp://10.0.0.1:53", &disabled, false},
+		{"udp enabled", "udp://10.0.0.1:53", &enabled, true},
+		{"tcp ignores defaults", "tcp://10.0.0.1:80", nil, false},
+	}
+	for _, tt := range tests {
+		s := &Service{
+			Name:           tt.name,
+			Address:        tt.address,
+			Globals:        globals,
+			ServiceOptions: ServiceOptions{OnePacket: tt.onePacket},
+		}
+		if res := s.Effective().IsOnePacket(); res != tt.expected {
+			t.Errorf("%s: got onePacket %v, expected %v", tt.name, res, tt.expected)
+		}
+	}
+}
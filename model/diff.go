@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeKind classifies a single difference between two ipvsctl models
+type ChangeKind string
+
+// Kinds of changes between ipvsctl models
+const (
+	ServiceAdded       ChangeKind = "service.added"
+	ServiceRemoved     ChangeKind = "service.removed"
+	ServiceChanged     ChangeKind = "service.changed"
+	DestinationAdded   ChangeKind = "destination.added"
+	DestinationRemoved ChangeKind = "destination.removed"
+	DestinationChanged ChangeKind = "destination.changed"
+)
+
+// ModelChange is a single difference between two ipvsctl models. Services
+// are identified by their address, destinations by address within a service.
+type ModelChange struct {
+	Kind        ChangeKind
+	Service     string // service address
+	ServiceName string // ipvsmesh service name, if known
+	Destination string // destination address, for destination changes
+	Field       string // changed field, for *.changed
+	Old         string
+	New         string
+}
+
+// Diff compares m (the current model) to other (the new model) and returns
+// all differences in services, destinations and their options.
+func (m IPVSModelStruct) Diff(other IPVSModelStruct) []ModelChange {
+	res := make([]ModelChange, 0)
+
+	cur := m.servicesByAddress()
+	next := other.servicesByAddress()
+
+	for _, address := range sortedKeys(cur, next) {
+		cs, inCur := cur[address]
+		ns, inNext := next[address]
+
+		switch {
+		case inCur && !inNext:
+			res = append(res, ModelChange{Kind: ServiceRemoved, Service: address, ServiceName: str(cs["ipvsmesh.service.name"])})
+			for _, d := range sortedDestinationAddresses(destinationsByAddress(cs)) {
+				res = append(res, ModelChange{Kind: DestinationRemoved, Service: address, ServiceName: str(cs["ipvsmesh.service.name"]), Destination: d})
+			}
+		case !inCur && inNext:
+			res = append(res, ModelChange{Kind: ServiceAdded, Service: address, ServiceName: str(ns["ipvsmesh.service.name"])})
+			for _, d := range sortedDestinationAddresses(destinationsByAddress(ns)) {
+				res = append(res, ModelChange{Kind: DestinationAdded, Service: address, ServiceName: str(ns["ipvsmesh.service.name"]), Destination: d})
+			}
+		default:
+			res = append(res, diffService(address, cs, ns)...)
+		}
+	}
+
+	return res
+}
+
+func diffService(address string, cs, ns map[string]interface{}) []ModelChange {
+	res := make([]ModelChange, 0)
+	name := str(ns["ipvsmesh.service.name"])
+
+	for _, field := range []string{"sched", "schedFlags", "persistenceTimeout", "netmask", "onePacket", "ipvsmesh.service.name"} {
+		if str(cs[field]) != str(ns[field]) {
+			res = append(res, ModelChange{Kind: ServiceChanged, Service: address, ServiceName: name, Field: field, Old: str(cs[field]), New: str(ns[field])})
+		}
+	}
+
+	cds := destinationsByAddress(cs)
+	nds := destinationsByAddress(ns)
+	all := make(map[string]map[string]interface{}, len(cds)+len(nds))
+	for k, v := range cds {
+		all[k] = v
+	}
+	for k, v := range nds {
+		all[k] = v
+	}
+
+	for _, d := range sortedDestinationAddresses(all) {
+		cd, inCur := cds[d]
+		nd, inNext := nds[d]
+
+		switch {
+		case inCur && !inNext:
+			res = append(res, ModelChange{Kind: DestinationRemoved, Service: address, ServiceName: name, Destination: d})
+		case !inCur && inNext:
+			res = append(res, ModelChange{Kind: DestinationAdded, Service: address, ServiceName: name, Destination: d})
+		default:
+			for _, field := range []string{"weight", "forward", "upperThreshold", "lowerThreshold"} {
+				if str(cd[field]) != str(nd[field]) {
+					res = append(res, ModelChange{Kind: DestinationChanged, Service: address, ServiceName: name, Destination: d, Field: field, Old: str(cd[field]), New: str(nd[field])})
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+func (m IPVSModelStruct) servicesByAddress() map[string]map[string]interface{} {
+	res := make(map[string]map[string]interface{})
+
+	services, _ := m["services"].([]interface{})
+	for _, serviceRaw := range services {
+		service, ok := serviceRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		res[str(service["address"])] = service
+	}
+	return res
+}
+
+func destinationsByAddress(service map[string]interface{}) map[string]map[string]interface{} {
+	res := make(map[string]map[string]interface{})
+
+	destinations, _ := service["destinations"].([]interface{})
+	for _, destinationRaw := range destinations {
+		destination, ok := destinationRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		res[str(destination["address"])] = destination
+	}
+	return res
+}
+
+func sortedKeys(a, b map[string]map[string]interface{}) []string {
+	m := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		m[k] = true
+	}
+	for k := range b {
+		m[k] = true
+	}
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func sortedDestinationAddresses(m map[string]map[string]interface{}) []string {
+	return sortedKeys(m, nil)
+}
+
+func str(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testModel builds an ipvsctl model of services
+func testModel(services ...map[string]interface{}) IPVSModelStruct {
+	res := make([]interface{}, len(services))
+	for idx, s := range services {
+		res[idx] = s
+	}
+	return IPVSModelStruct{"services": res}
+}
+
+// testService builds a service of an ipvsctl model with destinations
+// of weight 1000
+func testService(name, address string, destinations ...string) map[string]interface{} {
+	ds := make([]interface{}, len(destinations))
+	for idx, d := range destinations {
+		ds[idx] = map[string]interface{}{"address": d, "weight": 1000, "forward": "nat"}
+	}
+	return map[string]interface{}{
+		"address":               address,
+		"sched":                 "wrr",
+		"ipvsmesh.service.name": name,
+		"destinations":          ds,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	web := testService("web", "tcp://10.0.0.1:80", "tcp://20.0.0.1:80", "tcp://20.0.0.2:80")
+
+	rr := testService("web", "tcp://10.0.0.1:80", "tcp://20.0.0.1:80", "tcp://20.0.0.2:80")
+	rr["sched"] = "rr"
+
+	weighted := testService("web", "tcp://10.0.0.1:80", "tcp://20.0.0.1:80", "tcp://20.0.0.2:80")
+	weighted["destinations"].([]interface{})[1].(map[string]interface{})["weight"] = 0
+
+	tests := []struct {
+		name     string
+		cur      IPVSModelStruct
+		next     IPVSModelStruct
+		expected []ModelChange
+	}{
+		{
+			name:     "unchanged",
+			cur:      testModel(web),
+			next:     testModel(testService("web", "tcp://10.0.0.1:80", "tcp://20.0.0.2:80", "tcp://20.0.0.1:80")),
+			expected: []ModelChange{},
+		},
+		{
+			name: "service added",
+			cur:  IPVSModelStruct{},
+			next: testModel(testService("dns", "udp://10.0.0.2:53", "udp://20.0.0.1:53")),
+			expected: []ModelChange{
+				{Kind: ServiceAdded, Service: "udp://10.0.0.2:53", ServiceName: "dns"},
+				{Kind: DestinationAdded, Service: "udp://10.0.0.2:53", ServiceName: "dns", Destination: "udp://20.0.0.1:53"},
+			},
+		},
+		{
+			name: "service removed",
+			cur:  testModel(web),
+			next: testModel(),
+			expected: []ModelChange{
+				{Kind: ServiceRemoved, Service: "tcp://10.0.0.1:80", ServiceName: "web"},
+				{Kind: DestinationRemoved, Service: "tcp://10.0.0.1:80", ServiceName: "web", Destination: "tcp://20.0.0.1:80"},
+				{Kind: DestinationRemoved, Service: "tcp://10.0.0.1:80", ServiceName: "web", Destination: "tcp://20.0.0.2:80"},
+			},
+		},
+		{
+			name: "service field changed",
+			cur:  testModel(web),
+			next: testModel(rr),
+			expected: []ModelChange{
+				{Kind: ServiceChanged, Service: "tcp://10.0.0.1:80", ServiceName: "web", Field: "sched", Old: "wrr", New: "rr"},
+			},
+		},
+		{
+			name: "destinations added and removed",
+			cur:  testModel(web),
+			next: testModel(testService("web", "tcp://10.0.0.1:80", "tcp://20.0.0.2:80", "tcp://20.0.0.3:80")),
+			expected: []ModelChange{
+				{Kind: DestinationRemoved, Service: "tcp://10.0.0.1:80", ServiceName: "web", Destination: "tcp://20.0.0.1:80"},
+				{Kind: DestinationAdded, Service: "tcp://10.0.0.1:80", ServiceName: "web", Destination: "tcp://20.0.0.3:80"},
+			},
+		},
+		{
+			name: "destination field changed",
+			cur:  testModel(web),
+			next: testModel(weighted),
+			expected: []ModelChange{
+				{Kind: DestinationChanged, Service: "tcp://10.0.0.1:80", ServiceName: "web", Destination: "tcp://20.0.0.2:80", Field: "weight", Old: "1000", New: "0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		res := tt.cur.Diff(tt.next)
+		if !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("%s: got %+v, expected %+v", tt.name, res, tt.expected)
+		}
+	}
+}
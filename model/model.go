@@ -1,5 +1,9 @@
 package model
 
+import (
+	"fmt"
+)
+
 // Service describes an IPVS service entry
 type Service struct {
 	// Name of a service
@@ -11,14 +15,9 @@ type Service struct {
 	// Type of this service, in terms of plugin types
 	Type string `yaml:"type"`
 
-	// ipvsctl-style scheduler name, default: wrr
-	SchedName string `yaml:"sched,omitempty"`
-
-	// ipvsctl-style initial weight, default: 1000
-	Weight int `yaml:"weight,omitempty"`
-
-	// ipvsctl-style type of forward: nat, direct, tunnel
-	Forward string `yaml:"forward,omitempty"` // default: nat
+	// Options of the ipvs service, unset options are taken
+	// from globals.defaults, see Effective
+	ServiceOptions `yaml:",inline"`
 
 	// Additional labels to target this service
 	Labels map[string]string `yaml:"labels,omitempty"`
@@ -27,9 +26,16 @@ type Service struct {
 	// plugins/* for concrete Spec structs
 	Spec map[interface{}]interface{} `yaml:"spec"`
 
-	Plugin PluginSpec
+	// Runtime is true for services registered at runtime via
+	// the local API instead of the configuration file
+	Runtime bool `yaml:"-"`
 
-	Globals *Globals // back ref to global structs
+	// Source is the configuration file this service has been read from
+	Source string `yaml:"-"`
+
+	Plugin PluginSpec `yaml:"-"`
+
+	Globals *Globals `yaml:"-"` // back ref to global structs
 }
 
 // Publisher is a construct to watch services for updates and
@@ -51,9 +57,12 @@ type Publisher struct {
 	// plugins/* for concrete Spec structs
 	Spec map[interface{}]interface{} `yaml:"spec"`
 
-	Plugin PluginSpec
+	// Source is the configuration file this publisher has been read from
+	Source string `yaml:"-"`
+
+	Plugin PluginSpec `yaml:"-"`
 
-	Globals *Globals // back ref to global structs
+	Globals *Globals `yaml:"-"` // back ref to global structs
 }
 
 // Globals contains global configuration entries for all ipvsmesh
@@ -61,6 +70,24 @@ type Globals struct {
 	Ipvsctl  IpvsctlConfig            `yaml:"ipvsctl,omitempty"`
 	Config   map[string]ConfigProfile `yaml:"configProfiles,omitempty"`
 	Settings map[string]string        `yaml:"settings"` // arbirtrary k/v settings, e.g. for plugins
+	Shutdown ShutdownConfig           `yaml:"shutdown,omitempty"`
+	Defaults Defaults                 `yaml:"defaults,omitempty"`
+}
+
+// Shutdown policies for ipvs services when the daemon stops
+const (
+	ShutdownKeep  = "keep"  // leave services as they are
+	ShutdownFlush = "flush" // remove all services, see below
+	ShutdownDrain = "drain" // set all weights to 0, wait for DrainPeriod, then flush
+)
+
+// ShutdownConfig describes what happens to ipvs services when the daemon stops.
+// ipvsctl apply is declarative, every model ipvsmesh applies replaces the whole
+// ipvs table. Services not created by ipvsmesh do not survive regular updates,
+// and flush and drain leave an empty ipvs table.
+type ShutdownConfig struct {
+	Policy      string `yaml:"policy,omitempty"`      // keep (default), flush or drain
+	DrainPeriod string `yaml:"drainPeriod,omitempty"` // for drain, e.g. 30s
 }
 
 // IpvsctlConfig describes the mode-of-operation for applying
@@ -72,19 +99,75 @@ type IpvsctlConfig struct {
 }
 
 // ConfigProfile defines configuration to an external source or
-// destination, e.g. docker daemon or etcd endpoint
+// destination, e.g. docker daemon or etcd endpoint. Plugins refer
+// to a profile by name.
 type ConfigProfile struct {
+	// Type of endpoint, e.g. docker. Plugins only accept profiles
+	// of their type, an empty type is accepted by all plugins.
+	Type string `yaml:"type,omitempty"`
+
 	URL string `yaml:"url"`
+
+	TLS *TLSProfile `yaml:"tls,omitempty"`
+
+	// Options are additional, type-specific settings
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+// TLSProfile contains TLS material for connecting to an endpoint
+type TLSProfile struct {
+	CACert             string `yaml:"cacert,omitempty"`
+	Cert               string `yaml:"cert,omitempty"`
+	Key                string `yaml:"key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Profile returns the configuration profile of given name. It is an error
+// if the profile does not exist, or if it is of another type than profileType.
+func (g *Globals) Profile(name, profileType string) (*ConfigProfile, error) {
+	p, ex := g.Config[name]
+	if !ex {
+		return nil, fmt.Errorf("configuration profile %s not found", name)
+	}
+	if p.Type != "" && p.Type != profileType {
+		return nil, fmt.Errorf("configuration profile %s is of type %s, expected %s", name, p.Type, profileType)
+	}
+	return &p, nil
 }
 
+// Parameter is a source of values which can be referenced as
+// ${name.key} within service addresses, service specs and
+// publisher specs.
+type Parameter struct {
+	Type   string `yaml:"type"`             // localNetwork, env or file
+	File   string `yaml:"file,omitempty"`   // for file, a yaml file with keys and values
+	Secret bool   `yaml:"secret,omitempty"` // values are redacted from output
+}
+
+// Versions of the configuration layout
+const (
+	// APIVersionV1 has been used without an apiVersion field. Service
+	// addresses may be given within the spec of a service.
+	APIVersionV1 = "ipvsmesh/v1"
+
+	// APIVersionV2 requires service addresses at service level
+	APIVersionV2 = "ipvsmesh/v2"
+
+	CurrentAPIVersion = APIVersionV2
+)
+
 // IPVSMeshConfig is the main confoguration structure. It contains
 // Global definitions, a set of services and a set of publishers.
 // Although this is not checked, a publisher without services does
 // not make sense.
 type IPVSMeshConfig struct {
-	Globals    Globals      `yaml:"globals,omitempty"`
-	Services   []*Service   `yaml:"services,omitempty"`
-	Publishers []*Publisher `yaml:"publishers,omitempty"`
+	// APIVersion is the version of the configuration layout. Older
+	// layouts are migrated to CurrentAPIVersion when read.
+	APIVersion string               `yaml:"apiVersion,omitempty"`
+	Parameters map[string]Parameter `yaml:"parameters,omitempty"`
+	Globals    Globals              `yaml:"globals,omitempty"`
+	Services   []*Service           `yaml:"services,omitempty"`
+	Publishers []*Publisher         `yaml:"publishers,omitempty"`
 }
 
 //
@@ -98,6 +181,9 @@ type PluginSpec interface {
 	// Name returns the name of the plugin
 	Name() string
 
+	// Validate checks the spec for errors without initializing the plugin.
+	Validate() error
+
 	// Initializes the plugin with a ref to the globals struct, so the plugin
 	// can pull out settings from it.
 	Initialize(globals *Globals) error
@@ -125,6 +211,13 @@ type PluginSpec interface {
 	PushUpwardData(data UpwardData) error
 }
 
+// PluginCloser is implemented by plugins which hold resources, such
+// as clients, that must be released when the plugin is discarded.
+type PluginCloser interface {
+	// Close releases all resources of the plugin
+	Close() error
+}
+
 // DownwardBackendServer contains all data regarding a concrete
 // endpoint, with an address string suitable for ipvsctl's model.
 // It may contain additional data (e.g. ids) in a map.
@@ -133,9 +226,15 @@ type DownwardBackendServer struct {
 	// feed ipvsctl with it
 	Address string
 
-	// Dynamic weight if assigned
+	// Dynamic weight if assigned, -1 to use the weight
+	// of the service or its defaults
 	Weight int
 
+	// Connection thresholds of this backend, 0 to use
+	// the thresholds of the service or its defaults
+	UpperThreshold int
+	LowerThreshold int
+
 	// AdditionalInfo contains metadata such as the container id
 	AdditionalInfo map[string]string
 }
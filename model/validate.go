@@ -0,0 +1,144 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schedulers are the ipvs scheduler names accepted by ipvsctl
+var Schedulers = []string{"rr", "wrr", "lc", "wlc", "lblc", "lblcr", "dh", "sh", "sed", "nq", "fo", "ovf", "mh"}
+
+// Forwards are the ipvs forwarding methods accepted by ipvsctl
+var Forwards = []string{"nat", "direct", "tunnel"}
+
+// SchedFlags are the ipvs scheduler flags accepted by ipvsctl, with
+// the scheduler they apply to
+var SchedFlags = map[string]string{
+	"sh-fallback": "sh",
+	"sh-port":     "sh",
+	"mh-fallback": "mh",
+	"mh-port":     "mh",
+}
+
+// labelRegexp matches label keys and non-empty label values
+var labelRegexp = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_./]*[A-Za-z0-9])?$`)
+
+const maxLabelLength = 63
+
+// ValidateAddress checks an ipvsctl-style service address, e.g.
+// tcp://10.0.0.1:8000 or 10.0.0.1:8000
+func ValidateAddress(address string) error {
+	if address == "" {
+		return fmt.Errorf("missing address")
+	}
+	hostPort := address
+	if idx := strings.Index(address, "://"); idx >= 0 {
+		switch proto := address[:idx]; proto {
+		case "tcp", "udp", "sctp":
+		default:
+			return fmt.Errorf("invalid protocol %s in address %s", proto, address)
+		}
+		hostPort = address[idx+3:]
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %s", address, err)
+	}
+	if net.ParseIP(host) == nil {
+		return fmt.Errorf("invalid address %s: %s is not an ip address", address, host)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid address %s: invalid port %s", address, port)
+	}
+	return nil
+}
+
+// ValidateScheduler checks an ipvs scheduler name
+func ValidateScheduler(sched string) error {
+	if !contains(Schedulers, sched) {
+		return fmt.Errorf("invalid scheduler %s, must be one of %s", sched, strings.Join(Schedulers, ", "))
+	}
+	return nil
+}
+
+// ValidateForward checks an ipvs forwarding method
+func ValidateForward(forward string) error {
+	if !contains(Forwards, forward) {
+		return fmt.Errorf("invalid forward %s, must be one of %s", forward, strings.Join(Forwards, ", "))
+	}
+	return nil
+}
+
+// ValidateSchedFlags checks that flags are known and apply to sched.
+// An empty sched is not checked against.
+func ValidateSchedFlags(sched string, flags []string) error {
+	for _, flag := range flags {
+		flagSched, ex := SchedFlags[flag]
+		if !ex {
+			names := make([]string, 0, len(SchedFlags))
+			for name := range SchedFlags {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("invalid scheduler flag %s, must be one of %s", flag, strings.Join(names, ", "))
+		}
+		if sched != "" && sched != flagSched {
+			return fmt.Errorf("scheduler flag %s requires scheduler %s", flag, flagSched)
+		}
+	}
+	return nil
+}
+
+// ValidateNetmask checks a persistence netmask, which is either an
+// IPv4 netmask such as 255.255.255.0 or an IPv6 prefix length
+func ValidateNetmask(netmask string) error {
+	if n, err := strconv.Atoi(netmask); err == nil {
+		if n < 1 || n > 128 {
+			return fmt.Errorf("invalid prefix length %d, must be 1..128", n)
+		}
+		return nil
+	}
+	ip := net.ParseIP(netmask).To4()
+	if ip == nil {
+		return fmt.Errorf("invalid netmask %s", netmask)
+	}
+	if _, bits := net.IPMask(ip).Size(); bits == 0 {
+		return fmt.Errorf("invalid netmask %s, bits must be contiguous", netmask)
+	}
+	return nil
+}
+
+// ValidateLabels checks keys and values of labels or label selectors.
+// Values may be empty.
+func ValidateLabels(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := labels[k]
+		if len(k) > maxLabelLength || !labelRegexp.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+		if v != "" && (len(v) > maxLabelLength || !labelRegexp.MatchString(v)) {
+			return fmt.Errorf("invalid value %q of label %s", v, k)
+		}
+	}
+	return nil
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
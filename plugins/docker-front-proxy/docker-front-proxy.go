@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	log "github.com/sirupsen/logrus"
 
@@ -14,12 +15,19 @@ import (
 	client "github.com/docker/docker/client"
 )
 
+var logger = logging.Component("plugin:dockerFrontProxy")
+
 // Spec is the spec subpart of a service for the docker front proxy plugin
 type Spec struct {
 	MatchLabels    map[string]string     `yaml:"matchLabels"`
 	DynamicWeights []*DynamicWeightsSpec `yaml:"dynamicWeights,omitempty"`
 
+	// ConfigurationProfile names a profile of type docker in globals.
+	// Without it, the docker client is configured from DOCKER_* env vars.
+	ConfigurationProfile string `yaml:"configurationProfile,omitempty"`
+
 	mu           sync.Mutex
+	profile      *model.ConfigProfile
 	dockerClient *client.Client
 }
 
@@ -30,8 +38,39 @@ type DynamicWeightsSpec struct {
 	MatchLabels map[string]string `yaml:"matchLabels"`
 }
 
+// Validate checks label selectors and dynamic weights
+func (s *Spec) Validate() error {
+	if err := model.ValidateLabels(s.MatchLabels); err != nil {
+		return fmt.Errorf("matchLabels: %s", err)
+	}
+	for idx, dw := range s.DynamicWeights {
+		if dw == nil {
+			continue
+		}
+		if dw.Weight < 0 {
+			return fmt.Errorf("dynamicWeights[%d]: weight must not be negative", idx)
+		}
+		if err := model.ValidateLabels(dw.MatchLabels); err != nil {
+			return fmt.Errorf("dynamicWeights[%d].matchLabels: %s", idx, err)
+		}
+	}
+	return nil
+}
+
 // Initialize the plugin
 func (s *Spec) Initialize(globals *model.Globals) error {
+	if s.ConfigurationProfile != "" {
+		p, err := globals.Profile(s.ConfigurationProfile, ProfileType)
+		if err != nil {
+			return err
+		}
+		if err := validateOptions(p); err != nil {
+			return fmt.Errorf("configuration profile %s: %s", s.ConfigurationProfile, err)
+		}
+		s.mu.Lock()
+		s.profile = p
+		s.mu.Unlock()
+	}
 	return s.initialize()
 }
 
@@ -41,16 +80,33 @@ func (s *Spec) initialize() error {
 
 	if s.dockerClient == nil {
 		var err error
-		s.dockerClient, err = client.NewEnvClient()
+		if s.profile != nil {
+			s.dockerClient, err = newDockerClient(s.profile)
+		} else {
+			s.dockerClient, err = client.NewEnvClient()
+		}
 		if err != nil {
-			log.WithField("err", err).Error("docker-front-proxy: unable to create docker client")
+			logger.WithField("err", err).Error("docker-front-proxy: unable to create docker client")
 			return err
 		}
-		log.WithField("docker", s.dockerClient).Trace("docker-front-proxy: Docker Client")
+		logger.WithField("docker", s.dockerClient).Trace("docker-front-proxy: Docker Client")
 	}
 	return nil
 }
 
+// Close releases the docker client
+func (s *Spec) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dockerClient == nil {
+		return nil
+	}
+	err := s.dockerClient.Close()
+	s.dockerClient = nil
+	return err
+}
+
 // Name returns the plugin name
 func (s *Spec) Name() string {
 	return "dockerFrontProxy"
@@ -81,7 +137,7 @@ func (s *Spec) GetDownwardData() ([]model.DownwardBackendServer, error) {
 	}
 	containers, err := s.dockerClient.ContainerList(ctx, opts)
 	if err != nil {
-		log.WithField("err", err).Error("docker-front-proxy: unable to query containers")
+		logger.WithField("err", err).Error("docker-front-proxy: unable to query containers")
 	}
 
 	numRunning := 0
@@ -110,7 +166,7 @@ func (s *Spec) GetDownwardData() ([]model.DownwardBackendServer, error) {
 			}
 		}
 
-		log.WithFields(log.Fields{
+		logger.WithFields(log.Fields{
 			"id":     container.ID,
 			"state":  container.State,
 			"status": container.Status,
@@ -166,7 +222,7 @@ func (s *Spec) HasUpwardInterface() bool {
 // RunNotificationLoop connects to docker daemon and waits for
 // container events. Each matching event will trigger an update on notCh
 func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}) error {
-	log.WithField("Name", s.Name()).Debug("docker-front-proxy: Starting notification loop")
+	logger.WithField("Name", s.Name()).Debug("docker-front-proxy: Starting notification loop")
 
 	err := s.initialize()
 	if err != nil {
@@ -174,7 +230,15 @@ func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}
 		return err
 	}
 
-	ctx, _ := context.WithCancel(context.Background())
+	s.mu.Lock()
+	watch, pollInterval := watchEvents(s.profile)
+	s.mu.Unlock()
+	if !watch {
+		return s.runPollLoop(notChan, quitChan, pollInterval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// listen for container messages only, from containers with given labels.
 	args := filters.NewArgs()
@@ -182,7 +246,7 @@ func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}
 	for k, v := range s.MatchLabels {
 		args.Add("label", fmt.Sprintf("%s=%s", k, v))
 	}
-	log.WithField("filters", args).Trace("docker-front-proxy: watching docker events")
+	logger.WithField("filters", args).Trace("docker-front-proxy: watching docker events")
 
 	msgs, errs := s.dockerClient.Events(ctx, types.EventsOptions{
 		Filters: args,
@@ -191,19 +255,42 @@ func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}
 	for {
 		select {
 		case err := <-errs:
-			log.WithField("err", err).Error("docker-front-proxy: docker client error")
+			logger.WithField("err", err).Error("docker-front-proxy: docker client error")
 			// TODO: try reconnect?
 			return nil
 		case msg := <-msgs:
 			if msg.Action == "start" || msg.Action == "restart" || msg.Action == "stop" || msg.Action == "die" || msg.Action == "pause" || msg.Action == "unpause" {
-				log.WithField("msg", msg).Trace("docker-front-proxy: docker client message")
+				logger.WithField("msg", msg).Trace("docker-front-proxy: docker client message")
 				go func() {
 					<-time.After(50 * time.Millisecond)
 					notChan <- struct{}{}
 				}()
 			}
 		case <-quitChan:
-			log.WithField("Name", s.Name()).Debug("docker-front-proxy: Stopped notification loop")
+			logger.WithField("Name", s.Name()).Debug("docker-front-proxy: Stopped notification loop")
+			return nil
+		}
+	}
+}
+
+// runPollLoop triggers an update on notChan periodically, for profiles
+// which do not watch docker events
+func (s *Spec) runPollLoop(notChan chan struct{}, quitChan chan struct{}, interval time.Duration) error {
+	logger.WithField("interval", interval).Debug("docker-front-proxy: polling containers")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case notChan <- struct{}{}:
+			case <-quitChan:
+				return nil
+			}
+		case <-quitChan:
+			logger.WithField("Name", s.Name()).Debug("docker-front-proxy: Stopped notification loop")
 			return nil
 		}
 	}
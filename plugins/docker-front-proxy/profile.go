@@ -0,0 +1,101 @@
+package dockerfrontproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+	client "github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// ProfileType is the type of configuration profiles accepted by this plugin
+const ProfileType = "docker"
+
+// Options of a docker configuration profile
+const (
+	optionAPIVersion   = "apiVersion"   // docker API version, default: client default
+	optionWatchEvents  = "watchEvents"  // watch docker events (default) or poll containers
+	optionPollInterval = "pollInterval" // interval when not watching events, default: 30s
+)
+
+const defaultPollInterval = 30 * time.Second
+
+// newDockerClient creates a docker client for the endpoint of given profile.
+// Supported url schemes are unix, file (as unix), tcp, http and https.
+func newDockerClient(p *model.ConfigProfile) (*client.Client, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var host string
+	switch u.Scheme {
+	case "unix", "file":
+		host = fmt.Sprintf("unix://%s", u.Path)
+	case "tcp", "http", "https":
+		host = fmt.Sprintf("tcp://%s", u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported url scheme for docker: %s", u.Scheme)
+	}
+
+	var httpClient *http.Client
+	if p.TLS != nil {
+		tlsc, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             p.TLS.CACert,
+			CertFile:           p.TLS.Cert,
+			KeyFile:            p.TLS.Key,
+			InsecureSkipVerify: p.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsc,
+			},
+		}
+	} else if u.Scheme == "https" {
+		return nil, fmt.Errorf("https url needs tls settings in profile")
+	}
+
+	version := p.Options[optionAPIVersion]
+	if version == "" {
+		version = client.DefaultVersion
+	}
+
+	return client.NewClient(host, version, httpClient, nil)
+}
+
+// validateOptions checks the options of a profile
+func validateOptions(p *model.ConfigProfile) error {
+	if v, ex := p.Options[optionWatchEvents]; ex {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid option %s: %s", optionWatchEvents, err)
+		}
+	}
+	if v, ex := p.Options[optionPollInterval]; ex {
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid option %s: %s", optionPollInterval, err)
+		}
+	}
+	return nil
+}
+
+// watchEvents returns true if the notification loop watches docker
+// events, and otherwise the interval to poll containers with.
+func watchEvents(p *model.ConfigProfile) (bool, time.Duration) {
+	if p == nil {
+		return true, 0
+	}
+	if v, err := strconv.ParseBool(p.Options[optionWatchEvents]); err != nil || v {
+		return true, 0
+	}
+	if d, err := time.ParseDuration(p.Options[optionPollInterval]); err == nil && d > 0 {
+		return false, d
+	}
+	return false, defaultPollInterval
+}
@@ -1,12 +1,16 @@
 package filepublisher
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
-	log "github.com/sirupsen/logrus"
 )
 
+var logger = logging.Component("plugin:filePublisher")
+
 // Spec is the spec subpart of a service for the etcd publisher plugin
 type Spec struct {
 	MatchLabels map[string]string `yaml:"matchLabels"`
@@ -32,6 +36,17 @@ func (s *Spec) Name() string {
 	return "filePublisher"
 }
 
+// Validate checks that an output file is given
+func (s *Spec) Validate() error {
+	if s.OutputFile == "" {
+		return errors.New("outputFile must not be empty")
+	}
+	if err := model.ValidateLabels(s.MatchLabels); err != nil {
+		return fmt.Errorf("matchLabels: %s", err)
+	}
+	return nil
+}
+
 // Initialize the plugin
 func (s *Spec) Initialize(globals *model.Globals) error {
 	return nil
@@ -54,7 +69,7 @@ func (s *Spec) HasUpwardInterface() bool {
 }
 
 func (s *Spec) PushUpwardData(data model.UpwardData) error {
-	log.WithField("data", data).Debug("PushUpwardData ->")
+	logger.WithField("data", data).Debug("PushUpwardData ->")
 
 	// write to file
 
@@ -3,64 +3,88 @@ package plugins
 import (
 	"errors"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	dockerfrontproxy "github.com/aschmidt75/ipvsmesh/plugins/docker-front-proxy"
 	filepublisher "github.com/aschmidt75/ipvsmesh/plugins/file-publisher"
 	proxyfromfile "github.com/aschmidt75/ipvsmesh/plugins/proxy-from-file"
 	socketfrontproxy "github.com/aschmidt75/ipvsmesh/plugins/socket-front-proxy"
+	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
 
-// ReadPluginSpecByTypeString takes the spec part of a services and
-// returns a plugin spec object
-func ReadPluginSpecByTypeString(service *model.Service) (model.PluginSpec, error) {
+var logger = logging.Component("plugins")
 
-	b, err := yaml.Marshal(service.Spec)
-	if err != nil {
-		return nil, err
-	}
-
-	var res model.PluginSpec
-	if service.Type == "dockerFrontProxy" {
-		res = &dockerfrontproxy.Spec{}
-	}
-	if service.Type == "socketFrontProxy" {
-		res = &socketfrontproxy.Spec{}
-	}
-	if service.Type == "proxyFromFile" {
-		res = &proxyfromfile.Spec{}
+// NewServicePluginSpec returns an empty plugin spec object for
+// a service type
+func NewServicePluginSpec(serviceType string) (model.PluginSpec, error) {
+	switch serviceType {
+	case "dockerFrontProxy":
+		return &dockerfrontproxy.Spec{}, nil
+	case "socketFrontProxy":
+		return &socketfrontproxy.Spec{}, nil
+	case "proxyFromFile":
+		return &proxyfromfile.Spec{}, nil
 	}
+	return nil, errors.New("unknown service type, skipping spec")
+}
 
-	if res == nil {
-		return nil, errors.New("unknown service type, skipping spec")
+// NewPublisherPluginSpec returns an empty plugin spec object for
+// a publisher type
+func NewPublisherPluginSpec(publisherType string) (model.PluginSpec, error) {
+	switch publisherType {
+	case "filePublisher":
+		return &filepublisher.Spec{}, nil
 	}
+	return nil, errors.New("unknown publisher type, skipping spec")
+}
 
-	err = yaml.Unmarshal(b, res)
+// ReadPluginSpecByTypeString takes the spec part of a services and
+// returns a plugin spec object. Unknown fields within the spec are ignored
+// with a warning, use config.Validate to report them.
+func ReadPluginSpecByTypeString(service *model.Service) (model.PluginSpec, error) {
+	res, err := NewServicePluginSpec(service.Type)
 	if err != nil {
 		return nil, err
 	}
+	if err := readSpec("service", service.Name, service.Spec, res); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
+// ReadPublisherPluginSpecByTypeString takes the spec part of a publisher and
+// returns a plugin spec object. Unknown fields within the spec are ignored
+// with a warning, use config.Validate to report them.
 func ReadPublisherPluginSpecByTypeString(publisher *model.Publisher) (model.PluginSpec, error) {
-
-	b, err := yaml.Marshal(publisher.Spec)
+	res, err := NewPublisherPluginSpec(publisher.Type)
 	if err != nil {
 		return nil, err
 	}
-
-	var res model.PluginSpec
-	if publisher.Type == "filePublisher" {
-		res = &filepublisher.Spec{}
-	}
-
-	if res == nil {
-		return nil, errors.New("unknown publisher type, skipping spec")
+	if err := readSpec("publisher", publisher.Name, publisher.Spec, res); err != nil {
+		return nil, err
 	}
+	return res, nil
+}
 
-	err = yaml.Unmarshal(b, res)
+// readSpec decodes spec into res. Unknown fields are logged as a warning
+// for the service or publisher name, so that the daemon keeps running
+// configurations written for other versions.
+func readSpec(kind string, name string, spec map[interface{}]interface{}, res model.PluginSpec) error {
+	b, err := yaml.Marshal(spec)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return res, nil
+	strictErr := yaml.UnmarshalStrict(b, res)
+	if strictErr == nil {
+		return nil
+	}
+	if err := yaml.Unmarshal(b, res); err != nil {
+		return err
+	}
+	logger.WithFields(log.Fields{
+		kind:  name,
+		"err": strictErr,
+	}).Warn("Ignoring unknown fields of spec")
+	return nil
 }
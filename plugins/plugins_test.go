@@ -0,0 +1,38 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/aschmidt75/ipvsmesh/model"
+	proxyfromfile "github.com/aschmidt75/ipvsmesh/plugins/proxy-from-file"
+)
+
+func TestReadPluginSpecByTypeString(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[interface{}]interface{}
+		err  bool
+	}{
+		{"known fields", map[interface{}]interface{}{"file": "/tmp/b.txt", "defaultWeight": 10}, false},
+		{"unknown field", map[interface{}]interface{}{"file": "/tmp/b.txt", "defaultWeight": 10, "future": "x"}, false},
+		{"wrong type", map[interface{}]interface{}{"file": "/tmp/b.txt", "defaultWeight": "ten"}, true},
+	}
+	for _, test := range tests {
+		service := &model.Service{Name: "web", Type: "proxyFromFile", Spec: test.spec}
+		res, err := ReadPluginSpecByTypeString(service)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		spec := res.(*proxyfromfile.Spec)
+		if spec.File != "/tmp/b.txt" || spec.DefaultWeight != 10 {
+			t.Errorf("%s: got file %q, weight %d", test.name, spec.File, spec.DefaultWeight)
+		}
+	}
+}
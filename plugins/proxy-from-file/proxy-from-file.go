@@ -11,11 +11,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	"github.com/radovskyb/watcher"
 	log "github.com/sirupsen/logrus"
 )
 
+var logger = logging.Component("plugin:proxyFromFile")
+
 // Spec is the spec subpart of a service for the docker front proxy plugin
 type Spec struct {
 	File          string `yaml:"file"`
@@ -26,6 +29,20 @@ type Spec struct {
 	lastModTime time.Time
 }
 
+// Validate checks that a file of a known type is given
+func (s *Spec) Validate() error {
+	if s.File == "" {
+		return errors.New("file must not be empty")
+	}
+	if s.Type != "text" && s.Type != "json" {
+		return fmt.Errorf("invalid type %s, must be text or json", s.Type)
+	}
+	if s.DefaultWeight < 0 {
+		return errors.New("defaultWeight must not be negative")
+	}
+	return nil
+}
+
 // Initialize the plugin
 func (s *Spec) Initialize(globals *model.Globals) error {
 	return nil
@@ -89,26 +106,34 @@ func (s *Spec) getDownwardDataJSON(b []byte) ([]model.DownwardBackendServer, err
 				if ip, ok := ip0.(string); ex1 && ok {
 					if weight, ok2 := weight0.(float64); ex2 && ok2 {
 						// todo: validate data
-						res = append(res, model.DownwardBackendServer{
+						dbs := model.DownwardBackendServer{
 							Address: ip,
 							Weight:  int(weight),
-						})
+						}
+						// optional connection thresholds
+						if upper, ok := m["upperThreshold"].(float64); ok {
+							dbs.UpperThreshold = int(upper)
+						}
+						if lower, ok := m["lowerThreshold"].(float64); ok {
+							dbs.LowerThreshold = int(lower)
+						}
+						res = append(res, dbs)
 						added = true
 					} else {
-						log.WithField("m", m).Warn("weight not valid")
+						logger.WithField("m", m).Warn("weight not valid")
 					}
 				} else {
-					log.WithField("m", m).Warn("ip not valid")
+					logger.WithField("m", m).Warn("ip not valid")
 				}
 				if !added {
-					log.WithField("m", m).Warn("invalid data")
+					logger.WithField("m", m).Warn("invalid data")
 				}
 			default:
-				log.WithField("lx", lx).Trace("skipping, not a map.")
+				logger.WithField("lx", lx).Trace("skipping, not a map.")
 			}
 		}
 	default:
-		log.WithField("f", f).Trace("skipping, not a list.")
+		logger.WithField("f", f).Trace("skipping, not a list.")
 	}
 	return res, nil
 }
@@ -125,7 +150,7 @@ func (s *Spec) getDownwardDataText(b []byte) ([]model.DownwardBackendServer, err
 		// expect (IP|Host)[:PORT] [WEIGHT]
 		h, p, w, err := splitHostPortWeight(line)
 		if err != nil {
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				"err": err,
 				"l":   line,
 			}).Error("proxy-from-file: Skipping malformed line")
@@ -142,12 +167,16 @@ func (s *Spec) getDownwardDataText(b []byte) ([]model.DownwardBackendServer, err
 		if w == 0 {
 			w = s.DefaultWeight
 		}
+		if w == 0 {
+			// use service weight
+			w = -1
+		}
 
 		dbs := model.DownwardBackendServer{
 			Address: a,
 			Weight:  w,
 		}
-		log.WithFields(log.Fields{
+		logger.WithFields(log.Fields{
 			"l":    line,
 			"data": dbs,
 		}).Trace("proxy-from-file: processed line")
@@ -205,25 +234,25 @@ func (s *Spec) HasUpwardInterface() bool {
 
 // RunNotificationLoop ...
 func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}) error {
-	log.WithField("Name", s.Name()).Debug("proxy-from-file: Starting notification loop")
+	logger.WithField("Name", s.Name()).Debug("proxy-from-file: Starting notification loop")
 
 	w := watcher.New()
 	w.SetMaxEvents(1)
 	w.FilterOps(watcher.Write, watcher.Create, watcher.Remove)
 
 	if err := w.Add(s.File); err != nil {
-		log.WithField("err", err).Error("proxy-from-file: Unable to set up watcher")
+		logger.WithField("err", err).Error("proxy-from-file: Unable to set up watcher")
 	}
 
 	go func() {
 		w.Wait()
-		log.Debug("proxy-from-file: Initial config file read trigger")
+		logger.Debug("proxy-from-file: Initial config file read trigger")
 		w.TriggerEvent(watcher.Create, nil)
 	}()
 
 	go func() {
 		if err := w.Start(time.Millisecond * 100); err != nil {
-			log.WithField("err", err).Error("proxy-from-file: Unable to start watcher")
+			logger.WithField("err", err).Error("proxy-from-file: Unable to start watcher")
 		}
 
 	}()
@@ -231,7 +260,7 @@ func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}
 	for {
 		select {
 		case event := <-w.Event:
-			log.WithField("e", event).Debug("proxy-from-file: config file(s) changed")
+			logger.WithField("e", event).Debug("proxy-from-file: config file(s) changed")
 			info, err := os.Stat(s.File)
 			if err == nil {
 				mt := info.ModTime()
@@ -241,12 +270,12 @@ func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}
 				}
 			}
 		case err := <-w.Error:
-			log.WithField("err", err).Error("proxy-from-file: Watcher error")
+			logger.WithField("err", err).Error("proxy-from-file: Watcher error")
 		case <-w.Closed:
-			log.Info("proxy-from-file: Stopping Configuratiom Watcher")
+			logger.Info("proxy-from-file: Stopping Configuratiom Watcher")
 			return nil
 		case <-quitChan:
-			log.WithField("Name", s.Name()).Debug("proxy-from-file: Stopped notification loop")
+			logger.WithField("Name", s.Name()).Debug("proxy-from-file: Stopped notification loop")
 			return nil
 		}
 	}
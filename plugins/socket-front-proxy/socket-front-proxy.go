@@ -8,10 +8,13 @@ import (
 
 	"reflect"
 
+	"github.com/aschmidt75/ipvsmesh/logging"
 	"github.com/aschmidt75/ipvsmesh/model"
 	log "github.com/sirupsen/logrus"
 )
 
+var logger = logging.Component("plugin:socketFrontProxy")
+
 // Spec is the spec subpart of a service for the docker front proxy plugin
 type Spec struct {
 	MatchSocket MatchSocketSpec `yaml:"matchSocket"`
@@ -54,14 +57,28 @@ func (s *Spec) HasDownwardInterface() bool {
 	return true
 }
 
+// Validate checks the socket match
+func (s *Spec) Validate() error {
+	if _, _, err := net.ParseCIDR(s.MatchSocket.Address); err != nil {
+		return fmt.Errorf("matchSocket.address: %s", err)
+	}
+	if p := s.MatchSocket.Protocol; p != "" && p != "tcp" && p != "udp" {
+		return fmt.Errorf("matchSocket.protocol: invalid protocol %s, must be tcp or udp", p)
+	}
+	if s.MatchSocket.Ports.From > s.MatchSocket.Ports.To {
+		return fmt.Errorf("matchSocket.ports: from %d is greater than to %d", s.MatchSocket.Ports.From, s.MatchSocket.Ports.To)
+	}
+	return nil
+}
+
 // Initialize the plugin
 func (s *Spec) Initialize(globals *model.Globals) error {
-	log.Trace("socket-front-proxy: initialize")
+	logger.Trace("socket-front-proxy: initialize")
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.lastListeners = []Listener{}
+	s.lastListeners = nil
 
 	if s.MatchSocket.Protocol == "udp" {
 		s.procnetfile = "/proc/net/udp"
@@ -72,7 +89,7 @@ func (s *Spec) Initialize(globals *model.Globals) error {
 		v, ex := globals.Settings["socketFrontProxy.procnet.file"]
 		if ex {
 			s.procnetfile = v
-			log.WithField("procnetfile", v).Trace("Using different proc-net file")
+			logger.WithField("procnetfile", v).Trace("Using different proc-net file")
 		}
 	}
 	return nil
@@ -83,25 +100,38 @@ func (s *Spec) Initialize(globals *model.Globals) error {
 func (s *Spec) GetDownwardData() ([]model.DownwardBackendServer, error) {
 	res := make([]model.DownwardBackendServer, 0)
 
-	for _, listener := range s.lastListeners {
+	listeners := s.lastListeners
+	if listeners == nil {
+		// notification loop has not run yet, e.g. when querying
+		// a candidate configuration. Read listeners directly.
+		var err error
+		listeners, err = ParseProcNetTcpUdpFromFile(s.procnetfile)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	for _, listener := range listeners {
 		//log.WithField("l", listener).Trace("listener")
 		if listener.port >= s.MatchSocket.Ports.From && listener.port <= s.MatchSocket.Ports.To {
 
 			ip, ipnet, err := net.ParseCIDR(s.MatchSocket.Address)
 			if err != nil {
-				log.WithField("a", s.MatchSocket.Address).Error("socket-front-proxy: Not in CIDR form, skipping")
+				logger.WithField("a", s.MatchSocket.Address).Error("socket-front-proxy: Not in CIDR form, skipping")
 				continue
 			}
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				"ip":    ip,
 				"ipnet": ipnet,
 				"from":  s.MatchSocket.Address,
 			}).Trace("Parsed")
 			if ipnet.Contains(listener.ip) {
 				a := fmt.Sprintf("%s:%d", listener.ip, listener.port)
-				log.WithField("addr", a).Debug("socket-front-proxy: Matching ip/port")
+				logger.WithField("addr", a).Debug("socket-front-proxy: Matching ip/port")
 				res = append(res, model.DownwardBackendServer{
 					Address: a,
+					// no dynamic weights, use the weight of the service or its defaults
+					Weight: -1,
 				})
 			}
 		}
@@ -117,7 +147,7 @@ func (s *Spec) HasUpwardInterface() bool {
 
 // RunNotificationLoop monitors /proc/net/{tcp,udp} for changes. Each matching event will trigger an update on notCh
 func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}) error {
-	log.WithField("Name", s.Name()).Debug("socket-front-proxy: Starting notification loop")
+	logger.WithField("Name", s.Name()).Debug("socket-front-proxy: Starting notification loop")
 
 	err := s.initialize()
 	if err != nil {
@@ -132,16 +162,16 @@ func (s *Spec) RunNotificationLoop(notChan chan struct{}, quitChan chan struct{}
 
 			listeners, err = ParseProcNetTcpUdpFromFile(s.procnetfile)
 			if err != nil {
-				log.WithField("err", err).Error("Unable to read from proc net file")
+				logger.WithField("err", err).Error("Unable to read from proc net file")
 				continue
 			}
 			if reflect.DeepEqual(s.lastListeners, listeners) == false {
 				s.lastListeners = listeners
-				log.Trace("socket-front-proxy: Found port update")
+				logger.Trace("socket-front-proxy: Found port update")
 				notChan <- struct{}{}
 			}
 		case <-quitChan:
-			log.WithField("Name", s.Name()).Debug("socket-front-proxy: Stopped notification loop")
+			logger.WithField("Name", s.Name()).Debug("socket-front-proxy: Stopped notification loop")
 			return nil
 		}
 	}